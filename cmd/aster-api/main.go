@@ -8,11 +8,13 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Embed the tz database so job time zones resolve in slim images
 
 	"github.com/Franklyne-kibet/aster-scheduler/internal/api"
 	"github.com/Franklyne-kibet/aster-scheduler/internal/config"
 	"github.com/Franklyne-kibet/aster-scheduler/internal/db"
 	"github.com/Franklyne-kibet/aster-scheduler/internal/db/store"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/executor"
 	"go.uber.org/zap"
 )
 
@@ -47,9 +49,19 @@ func main() {
 	// Create stores
 	jobStore := store.NewJobStore(database.Pool())
 	runStore := store.NewRunStore(database.Pool())
+	runLogStore := store.NewRunLogStore(database.Pool())
+	leaseStore := store.NewLeaseStore(database.Pool())
+	backfillStore := store.NewBackfillStore(database.Pool())
+
+	// Executors validate type-specific job settings on create and update
+	// The API rejects jobs whose run_as account the workers would refuse
+	executors := executor.NewRegistry(logger, executor.Settings{
+		RunAsUsers:  cfg.JobRunAsUsers,
+		RunAsGroups: cfg.JobRunAsGroups,
+	})
 
 	// Create and start API server
-	server := api.NewServer(cfg, jobStore, runStore, logger)
+	server := api.NewServer(cfg, jobStore, runStore, runLogStore, leaseStore, backfillStore, executors, logger)
 
 	// Start server in goroutine
 	go func() {
@@ -2,11 +2,13 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Embed the tz database so job time zones resolve in slim images
 
 	"go.uber.org/zap"
 
@@ -30,7 +32,13 @@ func main() {
 	}
 	defer logger.Sync()
 
+	// Create scheduler ID used for leader election
+	hostname, _ := os.Hostname()
+	schedulerID := fmt.Sprintf("scheduler-%s-%d", hostname, os.Getpid())
+
 	logger.Info("Starting Aster Scheduler",
+		zap.String("scheduler_id", schedulerID),
+		zap.Duration("leader_election_ttl", cfg.LeaderElectionTTL),
 		zap.String("log_level", cfg.LogLevel))
 
 	// Connect to database
@@ -46,21 +54,22 @@ func main() {
 	// Create stores
 	jobStore := store.NewJobStore(database.Pool())
 	runStore := store.NewRunStore(database.Pool())
+	leaseStore := store.NewLeaseStore(database.Pool())
 
-	// Create scheduler
-	sched := scheduler.NewScheduler(jobStore, runStore, logger)
+	// Create scheduler that only acts while it holds the leader lease
+	sched := scheduler.NewScheduler(database.Pool(), jobStore, runStore, logger)
+	sched.SetLeaderElector(scheduler.NewLeaderElector(leaseStore, schedulerID, cfg.LeaderElectionTTL, logger))
+	sched.SetRunHeartbeatTimeout(cfg.RunHeartbeatTimeout)
 
-	// Channel to capture scheduler errors
-	schedErrCh := make(chan error, 1)
+	// Channel signalled when the scheduler returns
+	schedDone := make(chan error, 1)
 
 	// Start scheduler in goroutine
 	ctx, cancel = context.WithCancel(context.Background())
 	defer cancel()
 
 	go func() {
-		if err := sched.Run(ctx); err != nil {
-			schedErrCh <- err
-		}
+		schedDone <- sched.Run(ctx)
 	}()
 
 	// Wait for interrupt signal or scheduler error
@@ -70,15 +79,17 @@ func main() {
 	select {
 	case sig := <-quit:
 		logger.Info("Scheduler shutting down...", zap.String("signal", sig.String()))
-	case err := <-schedErrCh:
+	case err := <-schedDone:
 		logger.Fatal("Scheduler failed", zap.Error(err))
 	}
 
-	// Cancel scheduler context for graceful shutdown
+	// Let the current tick finish and the lease be released, within limits
 	cancel()
-
-	// Give scheduler some time to cleanup
-	time.Sleep(2 * time.Second)
+	select {
+	case <-schedDone:
+	case <-time.After(cfg.ShutdownGracePeriod):
+		logger.Warn("Scheduler did not stop within the shutdown grace period")
+	}
 
 	logger.Info("Scheduler exited")
 }
@@ -38,6 +38,7 @@ func main() {
 
 	logger.Info("Starting Aster Worker",
 		zap.String("worker_id", workerID),
+		zap.Int("pool_size", cfg.WorkerPoolSize),
 		zap.String("log_level", cfg.LogLevel))
 
 	// Connect to database
@@ -53,22 +54,31 @@ func main() {
 	// Create stores and executor
 	jobStore := store.NewJobStore(database.Pool())
 	runStore := store.NewRunStore(database.Pool())
-	exec := executor.NewExecutor(logger)
+	runLogStore := store.NewRunLogStore(database.Pool())
+	exec := executor.NewRegistry(logger, executor.Settings{
+		EnvAllowlist: cfg.JobEnvAllowlist,
+		EnvDenylist:  cfg.JobEnvDenylist,
+		CgroupPath:   cfg.JobCgroupPath,
+		RunAsUsers:   cfg.JobRunAsUsers,
+		RunAsGroups:  cfg.JobRunAsGroups,
+	})
 
 	// Create worker
 	w := worker.NewWorker(workerID, jobStore, runStore, exec, logger)
+	w.SetPoolSize(cfg.WorkerPoolSize)
+	w.SetRunLogStore(runLogStore)
+	w.SetHeartbeatInterval(cfg.RunHeartbeatInterval)
+	w.SetShutdownGracePeriod(cfg.ShutdownGracePeriod)
 
-	// Channel to capture worker errors
-	workerErrCh := make(chan error, 1)
+	// Channel signalled when the worker returns
+	workerDone := make(chan error, 1)
 
 	// Start worker in goroutine
 	ctx, cancel = context.WithCancel(context.Background())
 	defer cancel()
 
 	go func() {
-		if err := w.Run(ctx); err != nil {
-			workerErrCh <- err
-		}
+		workerDone <- w.Run(ctx)
 	}()
 
 	// Wait for interrupt signal
@@ -77,16 +87,17 @@ func main() {
 
 	select {
 	case sig := <-quit:
-		logger.Info("Worker shutting down...", zap.String("signal", sig.String()))
-	case err := <-workerErrCh:
+		logger.Info("Worker shutting down...",
+			zap.String("signal", sig.String()),
+			zap.Duration("shutdown_grace_period", cfg.ShutdownGracePeriod))
+	case err := <-workerDone:
 		logger.Fatal("Worker failed", zap.Error(err))
 	}
 
-	// Cancel worker context for graceful shutdown
+	// Stop claiming runs and wait for in-flight ones to drain; runs still
+	// going after the grace period are terminated and marked interrupted
 	cancel()
-
-	// Give worker some time to cleanup
-	time.Sleep(2 * time.Second)
+	<-workerDone
 
 	logger.Info("Worker exited")
 }
@@ -49,7 +49,7 @@ func runCRUDDemo(ctx context.Context, store *store.JobStore) error {
 	// 1. CREATE - Create a new job
 	fmt.Println("\n1. Creating a new job...")
 
-	timeout := 5 * time.Minute
+	timeout := types.Duration(5 * time.Minute)
 	job := &types.Job{
 		ID:          uuid.New(),
 		Name:        fmt.Sprintf("demo_job_%d", time.Now().Unix()),
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/common"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/db/store"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/scheduler"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// maxBackfillRuns bounds how many runs a single backfill may create
+const maxBackfillRuns = 10000
+
+// BackfillHandler handles backfill-related HTTP requests
+type BackfillHandler struct {
+	jobStore      *store.JobStore
+	backfillStore *store.BackfillStore
+	cronParser    *scheduler.CronParser
+	logger        *zap.Logger
+}
+
+// NewBackfillHandler creates a new backfill handler
+func NewBackfillHandler(jobStore *store.JobStore, backfillStore *store.BackfillStore, logger *zap.Logger) *BackfillHandler {
+	return &BackfillHandler{
+		jobStore:      jobStore,
+		backfillStore: backfillStore,
+		cronParser:    scheduler.NewCronParser(),
+		logger:        logger,
+	}
+}
+
+// backfillRequest is the body of a backfill request
+type backfillRequest struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Parallelism int       `json:"parallelism"`
+}
+
+// CreateBackfill handles POST /api/v1/jobs/{id}/backfill
+func (h *BackfillHandler) CreateBackfill(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := common.ParseUUID(idStr)
+	if err != nil {
+		common.WriteValidationError(w, "Invalid job ID format", h.logger)
+		return
+	}
+
+	var req backfillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.WriteValidationError(w, "Invalid JSON: "+err.Error(), h.logger)
+		return
+	}
+
+	// Validate the range
+	if req.Start.IsZero() || req.End.IsZero() {
+		common.WriteValidationError(w, "start and end are required", h.logger)
+		return
+	}
+	if req.End.Before(req.Start) {
+		common.WriteValidationError(w, "end must not be before start", h.logger)
+		return
+	}
+	if req.End.After(time.Now()) {
+		common.WriteValidationError(w, "end must not be in the future", h.logger)
+		return
+	}
+	if req.Parallelism < 0 {
+		common.WriteValidationError(w, "parallelism must not be negative", h.logger)
+		return
+	}
+	if req.Parallelism == 0 {
+		req.Parallelism = 1
+	}
+
+	job, err := h.jobStore.GetJob(r.Context(), id)
+	if err != nil {
+		if err.Error() == "job not found" {
+			common.WriteNotFoundError(w, "Job", h.logger)
+		} else {
+			h.logger.Error("Failed to get job for backfill", zap.Error(err))
+			common.WriteInternalError(w, h.logger)
+		}
+		return
+	}
+
+	loc, err := scheduler.LoadLocation(job.Timezone)
+	if err != nil {
+		h.logger.Error("Failed to load job time zone", zap.Error(err))
+		common.WriteInternalError(w, h.logger)
+		return
+	}
+
+	// One run per cron slot, scheduled at the slot's logical time
+	slots, err := h.cronParser.GetRunsBetween(job.CronExpr, req.Start, req.End, loc, maxBackfillRuns)
+	if err != nil {
+		common.WriteValidationError(w, "Invalid backfill range: "+err.Error(), h.logger)
+		return
+	}
+	if len(slots) == 0 {
+		common.WriteValidationError(w, "Invalid backfill range: no cron slots between start and end", h.logger)
+		return
+	}
+
+	runs := make([]*types.Run, len(slots))
+	for i, slot := range slots {
+		runs[i] = &types.Run{
+			Status:      types.RunStatusScheduled,
+			AttemptNum:  1,
+			ScheduledAt: slot,
+		}
+	}
+
+	backfill := &types.Backfill{
+		JobID:       job.ID,
+		StartAt:     req.Start,
+		EndAt:       req.End,
+		Parallelism: req.Parallelism,
+	}
+
+	if err := h.backfillStore.CreateBackfill(r.Context(), backfill, runs); err != nil {
+		h.logger.Error("Failed to create backfill", zap.Error(err))
+		common.WriteInternalError(w, h.logger)
+		return
+	}
+
+	h.logger.Info("Backfill created",
+		zap.String("backfill_id", backfill.ID.String()),
+		zap.String("job_id", job.ID.String()),
+		zap.String("job_name", job.Name),
+		zap.Int("runs", backfill.RunCount),
+		zap.Int("parallelism", backfill.Parallelism))
+
+	common.WriteJSON(w, http.StatusCreated, backfill, h.logger)
+}
+
+// GetBackfill handles GET /api/v1/backfills/{id}
+func (h *BackfillHandler) GetBackfill(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := common.ParseUUID(idStr)
+	if err != nil {
+		common.WriteValidationError(w, "Invalid backfill ID format", h.logger)
+		return
+	}
+
+	backfill, err := h.backfillStore.GetBackfill(r.Context(), id)
+	if err != nil {
+		if err.Error() == "backfill not found" {
+			common.WriteNotFoundError(w, "Backfill", h.logger)
+		} else {
+			h.logger.Error("Failed to get backfill", zap.Error(err))
+			common.WriteInternalError(w, h.logger)
+		}
+		return
+	}
+
+	common.WriteJSON(w, http.StatusOK, backfill, h.logger)
+}
@@ -2,13 +2,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
 	"github.com/Franklyne-kibet/aster-scheduler/internal/common"
 	"github.com/Franklyne-kibet/aster-scheduler/internal/db/store"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/executor"
 	"github.com/Franklyne-kibet/aster-scheduler/internal/scheduler"
 	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
 )
@@ -16,14 +20,18 @@ import (
 // JobHandler handles job-related HTTP requests
 type JobHandler struct {
 	jobStore   *store.JobStore
+	runStore   *store.RunStore
+	executors  *executor.Registry // Validates type-specific job settings
 	cronParser *scheduler.CronParser
 	logger     *zap.Logger
 }
 
 // NewJobHandler creates a new job handler
-func NewJobHandler(jobStore *store.JobStore, logger *zap.Logger) *JobHandler {
+func NewJobHandler(jobStore *store.JobStore, runStore *store.RunStore, executors *executor.Registry, logger *zap.Logger) *JobHandler {
 	return &JobHandler{
 		jobStore:   jobStore,
+		runStore:   runStore,
+		executors:  executors,
 		cronParser: scheduler.NewCronParser(),
 		logger:     logger,
 	}
@@ -43,7 +51,6 @@ func (h *JobHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
 	requiredFields := map[string]string{
 		"name":      job.Name,
 		"cron_expr": job.CronExpr,
-		"command":   job.Command,
 	}
 
 	if err := common.ValidateRequiredFields(requiredFields); err != nil {
@@ -57,13 +64,52 @@ func (h *JobHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Validate the job type and its settings
+	if err := h.executors.Validate(&job); err != nil {
+		common.WriteValidationError(w, "Invalid job type configuration: "+err.Error(), h.logger)
+		return
+	}
+
+	// Validate time zone
+	if _, err := scheduler.LoadLocation(job.Timezone); err != nil {
+		common.WriteValidationError(w, "Invalid timezone: "+err.Error(), h.logger)
+		return
+	}
+
+	// Validate retry policy
+	if err := scheduler.ValidateRetryPolicy(job.RetryPolicy); err != nil {
+		common.WriteValidationError(w, "Invalid retry policy: "+err.Error(), h.logger)
+		return
+	}
+
+	// Validate misfire policy
+	if err := scheduler.ValidateMisfirePolicy(job.MisfirePolicy); err != nil {
+		common.WriteValidationError(w, "Invalid misfire policy: "+err.Error(), h.logger)
+		return
+	}
+
+	// Validate concurrency settings
+	if err := scheduler.ValidateConcurrency(&job); err != nil {
+		common.WriteValidationError(w, "Invalid concurrency settings: "+err.Error(), h.logger)
+		return
+	}
+
 	// Set defaults
+	if job.Type == "" {
+		job.Type = executor.TypeCommand
+	}
+	if job.Timezone == "" {
+		job.Timezone = "UTC"
+	}
 	if job.Status == "" {
 		job.Status = types.JobStatusActive
 	}
 	if job.MaxRetries == 0 {
 		job.MaxRetries = 3
 	}
+	scheduler.ApplyRetryDefaults(&job.RetryPolicy)
+	scheduler.ApplyMisfireDefaults(&job.MisfirePolicy)
+	scheduler.ApplyConcurrencyDefaults(&job)
 	if job.Args == nil {
 		job.Args = []string{}
 	}
@@ -165,9 +211,11 @@ func (h *JobHandler) UpdateJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Preserve ID and timestamps
+	// Preserve ID and timestamps. next_run_at is only set below, when the
+	// schedule changes.
 	updatedJob.ID = existingJob.ID
 	updatedJob.CreatedAt = existingJob.CreatedAt
+	updatedJob.NextRunAt = nil
 
 	// Validate cron expression if it was changed
 	if updatedJob.CronExpr != "" && updatedJob.CronExpr != existingJob.CronExpr {
@@ -184,12 +232,76 @@ func (h *JobHandler) UpdateJob(w http.ResponseWriter, r *http.Request) {
 	if updatedJob.CronExpr == "" {
 		updatedJob.CronExpr = existingJob.CronExpr
 	}
+	if updatedJob.Type == "" {
+		updatedJob.Type = existingJob.Type
+	}
+	if updatedJob.Config == nil {
+		updatedJob.Config = existingJob.Config
+	}
 	if updatedJob.Command == "" {
 		updatedJob.Command = existingJob.Command
 	}
 	if updatedJob.Status == "" {
 		updatedJob.Status = existingJob.Status
 	}
+	if updatedJob.Timezone == "" {
+		updatedJob.Timezone = existingJob.Timezone
+	}
+	if updatedJob.RetryPolicy == (types.RetryPolicy{}) {
+		updatedJob.RetryPolicy = existingJob.RetryPolicy
+	}
+	if updatedJob.MisfirePolicy == (types.MisfirePolicy{}) {
+		updatedJob.MisfirePolicy = existingJob.MisfirePolicy
+	}
+	if updatedJob.ConcurrencyPolicy == "" {
+		updatedJob.ConcurrencyPolicy = existingJob.ConcurrencyPolicy
+	}
+
+	// Validate the job type and its settings
+	if err := h.executors.Validate(&updatedJob); err != nil {
+		common.WriteValidationError(w, "Invalid job type configuration: "+err.Error(), h.logger)
+		return
+	}
+
+	// Validate time zone
+	loc, err := scheduler.LoadLocation(updatedJob.Timezone)
+	if err != nil {
+		common.WriteValidationError(w, "Invalid timezone: "+err.Error(), h.logger)
+		return
+	}
+
+	// A new expression or time zone moves the next run to its next slot from
+	// now. Jobs that haven't been scheduled yet still run right away.
+	scheduleChanged := updatedJob.CronExpr != existingJob.CronExpr || updatedJob.Timezone != existingJob.Timezone
+	if scheduleChanged && existingJob.NextRunAt != nil {
+		nextRunAt, err := h.cronParser.NextInLocation(updatedJob.CronExpr, time.Now(), loc)
+		if err != nil {
+			common.WriteValidationError(w, "Invalid cron expression: "+err.Error(), h.logger)
+			return
+		}
+		updatedJob.NextRunAt = &nextRunAt
+	}
+
+	// Validate retry policy
+	if err := scheduler.ValidateRetryPolicy(updatedJob.RetryPolicy); err != nil {
+		common.WriteValidationError(w, "Invalid retry policy: "+err.Error(), h.logger)
+		return
+	}
+	scheduler.ApplyRetryDefaults(&updatedJob.RetryPolicy)
+
+	// Validate misfire policy
+	if err := scheduler.ValidateMisfirePolicy(updatedJob.MisfirePolicy); err != nil {
+		common.WriteValidationError(w, "Invalid misfire policy: "+err.Error(), h.logger)
+		return
+	}
+	scheduler.ApplyMisfireDefaults(&updatedJob.MisfirePolicy)
+
+	// Validate concurrency settings
+	if err := scheduler.ValidateConcurrency(&updatedJob); err != nil {
+		common.WriteValidationError(w, "Invalid concurrency settings: "+err.Error(), h.logger)
+		return
+	}
+	scheduler.ApplyConcurrencyDefaults(&updatedJob)
 
 	// Update job in database
 	if err := h.jobStore.UpdateJob(r.Context(), &updatedJob); err != nil {
@@ -198,6 +310,10 @@ func (h *JobHandler) UpdateJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if updatedJob.NextRunAt == nil {
+		updatedJob.NextRunAt = existingJob.NextRunAt
+	}
+
 	h.logger.Info("Job updated",
 		zap.String("job_id", updatedJob.ID.String()),
 		zap.String("job_name", updatedJob.Name))
@@ -205,6 +321,146 @@ func (h *JobHandler) UpdateJob(w http.ResponseWriter, r *http.Request) {
 	common.WriteJSON(w, http.StatusOK, updatedJob, h.logger)
 }
 
+// GetJobNextRuns handles GET /api/v1/jobs/{id}/next-runs
+func (h *JobHandler) GetJobNextRuns(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := common.ParseUUID(idStr)
+	if err != nil {
+		common.WriteValidationError(w, "Invalid job ID format", h.logger)
+		return
+	}
+
+	count := common.ParsePositiveIntWithDefault(r.URL.Query().Get("count"), 5)
+	if count > 100 {
+		count = 100
+	}
+
+	job, err := h.jobStore.GetJob(r.Context(), id)
+	if err != nil {
+		if err.Error() == "job not found" {
+			common.WriteNotFoundError(w, "Job", h.logger)
+		} else {
+			h.logger.Error("Failed to get job for next runs", zap.Error(err))
+			common.WriteInternalError(w, h.logger)
+		}
+		return
+	}
+
+	loc, err := scheduler.LoadLocation(job.Timezone)
+	if err != nil {
+		h.logger.Error("Failed to load job time zone", zap.Error(err))
+		common.WriteInternalError(w, h.logger)
+		return
+	}
+
+	// Times are returned in the job's zone so the offsets show DST changes
+	nextRuns, err := h.cronParser.GetNextNRunsInLocation(job.CronExpr, time.Now().In(loc), loc, count)
+	if err != nil {
+		h.logger.Error("Failed to calculate next runs", zap.Error(err))
+		common.WriteInternalError(w, h.logger)
+		return
+	}
+
+	response := nextRunsResponse{
+		JobID:    job.ID,
+		Timezone: job.Timezone,
+		NextRuns: nextRuns,
+	}
+
+	common.WriteJSON(w, http.StatusOK, response, h.logger)
+}
+
+// nextRunsResponse lists upcoming fire times for a job
+type nextRunsResponse struct {
+	JobID    uuid.UUID   `json:"job_id"`
+	Timezone string      `json:"timezone"`
+	NextRuns []time.Time `json:"next_runs"`
+}
+
+// triggerRequest is the optional body of a manual trigger request
+type triggerRequest struct {
+	Args        []string          `json:"args"`
+	Env         map[string]string `json:"env"`
+	Timeout     *types.Duration   `json:"timeout"`
+	TriggeredBy string            `json:"triggered_by"`
+}
+
+// TriggerJob handles POST /api/v1/jobs/{id}/trigger
+func (h *JobHandler) TriggerJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := common.ParseUUID(idStr)
+	if err != nil {
+		common.WriteValidationError(w, "Invalid job ID format", h.logger)
+		return
+	}
+
+	// The body is optional, an empty one runs the job as configured
+	var req triggerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		common.WriteValidationError(w, "Invalid JSON: "+err.Error(), h.logger)
+		return
+	}
+
+	job, err := h.jobStore.GetJob(r.Context(), id)
+	if err != nil {
+		if err.Error() == "job not found" {
+			common.WriteNotFoundError(w, "Job", h.logger)
+		} else {
+			h.logger.Error("Failed to get job for trigger", zap.Error(err))
+			common.WriteInternalError(w, h.logger)
+		}
+		return
+	}
+
+	// Fall back to the caller's address when they don't say who they are
+	triggeredBy := req.TriggeredBy
+	if triggeredBy == "" {
+		triggeredBy = common.ClientIP(r)
+	}
+
+	run := &types.Run{
+		JobID:       job.ID,
+		Status:      types.RunStatusScheduled,
+		AttemptNum:  1,
+		Trigger:     types.RunTriggerManual,
+		TriggeredBy: &triggeredBy,
+		ScheduledAt: time.Now(),
+	}
+	if req.Args != nil || len(req.Env) > 0 || req.Timeout != nil {
+		run.Overrides = &types.RunOverrides{
+			Args:    req.Args,
+			Env:     req.Env,
+			Timeout: req.Timeout,
+		}
+	}
+
+	// The job must still be valid with the overrides applied, so they can't
+	// fail the run once a worker picks it up
+	if err := h.executors.ValidateOverrides(job, run.Overrides); err != nil {
+		common.WriteValidationError(w, "Invalid overrides: "+err.Error(), h.logger)
+		return
+	}
+
+	// next_run_at is left alone, the schedule carries on as before
+	if err := h.runStore.CreateRun(r.Context(), run); err != nil {
+		h.logger.Error("Failed to create manual run", zap.Error(err))
+		common.WriteInternalError(w, h.logger)
+		return
+	}
+
+	h.logger.Info("Job triggered manually",
+		zap.String("run_id", run.ID.String()),
+		zap.String("job_id", job.ID.String()),
+		zap.String("job_name", job.Name),
+		zap.String("triggered_by", triggeredBy))
+
+	common.WriteJSON(w, http.StatusCreated, run, h.logger)
+}
+
 // DeleteJob handles DELETE /api/v1/jobs/{id}
 func (h *JobHandler) DeleteJob(w http.ResponseWriter, r *http.Request) {
 	// Extract job ID from URL
@@ -1,27 +1,45 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
 	"github.com/Franklyne-kibet/aster-scheduler/internal/common"
 	"github.com/Franklyne-kibet/aster-scheduler/internal/db/store"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+const (
+	// runLogPageSize is how many log chunks are read per query
+	runLogPageSize = 1000
+
+	// runLogPollInterval is how often followed logs check for new output
+	runLogPollInterval = 500 * time.Millisecond
+
+	// runLogKeepAlive is how often an idle log stream sends a comment so
+	// proxies don't close it
+	runLogKeepAlive = 15 * time.Second
 )
 
 // RunHandler handles run-related HTTP requests
 type RunHandler struct {
-	runStore *store.RunStore
-	logger   *zap.Logger
+	runStore    *store.RunStore
+	runLogStore *store.RunLogStore
+	logger      *zap.Logger
 }
 
 // NewRunHandler creates a new run handler
-func NewRunHandler(runStore *store.RunStore, logger *zap.Logger) *RunHandler {
+func NewRunHandler(runStore *store.RunStore, runLogStore *store.RunLogStore, logger *zap.Logger) *RunHandler {
 	return &RunHandler{
-		runStore: runStore,
-		logger:   logger,
+		runStore:    runStore,
+		runLogStore: runLogStore,
+		logger:      logger,
 	}
 }
 
@@ -50,27 +68,125 @@ func (h *RunHandler) GetRun(w http.ResponseWriter, r *http.Request) {
 	common.WriteJSON(w, http.StatusOK, run, h.logger)
 }
 
+// GetRunAttempts handles GET /api/v1/runs/{id}/attempts
+func (h *RunHandler) GetRunAttempts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := common.ParseUUID(idStr)
+	if err != nil {
+		common.WriteValidationError(w, "Invalid run ID format", h.logger)
+		return
+	}
+
+	runs, err := h.runStore.GetRunAttempts(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get run attempts", zap.Error(err))
+		common.WriteInternalError(w, h.logger)
+		return
+	}
+
+	if len(runs) == 0 {
+		common.WriteNotFoundError(w, "Run", h.logger)
+		return
+	}
+
+	common.WriteJSON(w, http.StatusOK, runs, h.logger)
+}
+
+// CancelRun handles POST /api/v1/runs/{id}/cancel
+func (h *RunHandler) CancelRun(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := common.ParseUUID(idStr)
+	if err != nil {
+		common.WriteValidationError(w, "Invalid run ID format", h.logger)
+		return
+	}
+
+	run, err := h.runStore.CancelRun(r.Context(), id)
+	if err != nil {
+		switch err.Error() {
+		case "run not found":
+			common.WriteNotFoundError(w, "Run", h.logger)
+		case "run already finished":
+			common.WriteError(w, http.StatusConflict, "Run already finished", h.logger)
+		default:
+			h.logger.Error("Failed to cancel run", zap.Error(err))
+			common.WriteInternalError(w, h.logger)
+		}
+		return
+	}
+
+	h.logger.Info("Run cancellation requested",
+		zap.String("run_id", run.ID.String()),
+		zap.String("job_id", run.JobID.String()),
+		zap.String("status", string(run.Status)))
+
+	// Runs a worker already owns are stopped by that worker shortly
+	status := http.StatusOK
+	if run.Status != types.RunStatusCancelled {
+		status = http.StatusAccepted
+	}
+
+	common.WriteJSON(w, status, run, h.logger)
+}
+
 // ListRuns handles GET /api/v1/runs
 func (h *RunHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	limitStr := r.URL.Query().Get("limit")
 	offsetStr := r.URL.Query().Get("offset")
 	jobIDStr := r.URL.Query().Get("job_id")
+	triggerStr := r.URL.Query().Get("trigger")
+	backfillIDStr := r.URL.Query().Get("backfill_id")
+	resultStr := r.URL.Query().Get("result")
 
 	limit := common.ParsePositiveIntWithDefault(limitStr, 50)
 	offset := common.ParseIntWithDefault(offsetStr, 0)
 
-	var jobID *uuid.UUID
+	var filter store.RunFilter
 	if jobIDStr != "" {
 		if parsed, err := common.ParseUUID(jobIDStr); err == nil {
-			jobID = &parsed
+			filter.JobID = &parsed
 		} else {
 			common.WriteValidationError(w, "Invalid job_id format", h.logger)
 			return
 		}
 	}
 
-	runs, err := h.runStore.ListRuns(r.Context(), jobID, limit, offset)
+	if triggerStr != "" {
+		trigger := types.RunTrigger(triggerStr)
+		switch trigger {
+		case types.RunTriggerSchedule, types.RunTriggerBackfill, types.RunTriggerManual:
+			filter.Trigger = &trigger
+		default:
+			common.WriteValidationError(w, "Invalid trigger: "+triggerStr, h.logger)
+			return
+		}
+	}
+
+	if backfillIDStr != "" {
+		if parsed, err := common.ParseUUID(backfillIDStr); err == nil {
+			filter.BackfillID = &parsed
+		} else {
+			common.WriteValidationError(w, "Invalid backfill_id format", h.logger)
+			return
+		}
+	}
+
+	// Runs whose result contains the given JSON object, such as {"rows":0}
+	if resultStr != "" {
+		var result map[string]any
+		if err := json.Unmarshal([]byte(resultStr), &result); err != nil || result == nil {
+			common.WriteValidationError(w, "Invalid result: must be a JSON object", h.logger)
+			return
+		}
+		filter.Result = json.RawMessage(resultStr)
+	}
+
+	runs, err := h.runStore.ListRuns(r.Context(), filter, limit, offset)
 	if err != nil {
 		h.logger.Error("Failed to list runs", zap.Error(err))
 		common.WriteInternalError(w, h.logger)
@@ -79,3 +195,163 @@ func (h *RunHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
 
 	common.WriteJSON(w, http.StatusOK, runs, h.logger)
 }
+
+// GetRunLogs handles GET /api/v1/runs/{id}/logs. With follow=true the logs
+// are streamed as server-sent events until the run finishes.
+func (h *RunHandler) GetRunLogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := common.ParseUUID(idStr)
+	if err != nil {
+		common.WriteValidationError(w, "Invalid run ID format", h.logger)
+		return
+	}
+
+	// Clients resume after the last log they saw
+	afterStr := r.URL.Query().Get("after")
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		afterStr = lastEventID
+	}
+	var after int64
+	if afterStr != "" {
+		after, err = strconv.ParseInt(afterStr, 10, 64)
+		if err != nil || after < 0 {
+			common.WriteValidationError(w, "Invalid after: must be a log ID", h.logger)
+			return
+		}
+	}
+
+	run, err := h.runStore.GetRun(r.Context(), id)
+	if err != nil {
+		if err.Error() == "run not found" {
+			common.WriteNotFoundError(w, "Run", h.logger)
+		} else {
+			h.logger.Error("Failed to get run", zap.Error(err))
+			common.WriteInternalError(w, h.logger)
+		}
+		return
+	}
+
+	if r.URL.Query().Get("follow") != "true" {
+		limit := common.ParsePositiveIntWithDefault(r.URL.Query().Get("limit"), runLogPageSize)
+		logs, err := h.runLogStore.ListRunLogs(r.Context(), id, after, min(limit, runLogPageSize))
+		if err != nil {
+			h.logger.Error("Failed to list run logs", zap.Error(err))
+			common.WriteInternalError(w, h.logger)
+			return
+		}
+		if logs == nil {
+			logs = []*types.RunLog{}
+		}
+
+		common.WriteJSON(w, http.StatusOK, logs, h.logger)
+		return
+	}
+
+	h.followRunLogs(w, r, run, after)
+}
+
+// followRunLogs streams a run's logs as server-sent events. Each chunk is a
+// "log" event whose id can be sent back as Last-Event-ID to resume. An "end"
+// event carrying the finished run closes the stream.
+func (h *RunHandler) followRunLogs(w http.ResponseWriter, r *http.Request, run *types.Run, after int64) {
+	ctx := r.Context()
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	ticker := time.NewTicker(runLogPollInterval)
+	defer ticker.Stop()
+	lastWrite := time.Now()
+
+	for {
+		// Read the status before the logs: workers store all logs before
+		// finishing a run, so a finished run has nothing left to send
+		var err error
+		run, err = h.runStore.GetRun(ctx, run.ID)
+		if err != nil {
+			if ctx.Err() == nil {
+				h.logger.Error("Failed to get run while following logs", zap.Error(err))
+			}
+			return
+		}
+
+		logs, err := h.runLogStore.ListRunLogs(ctx, run.ID, after, runLogPageSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				h.logger.Error("Failed to list run logs", zap.Error(err))
+			}
+			return
+		}
+
+		for _, log := range logs {
+			if err := writeEvent(w, strconv.FormatInt(log.ID, 10), "log", log); err != nil {
+				return
+			}
+			after = log.ID
+		}
+
+		if len(logs) < runLogPageSize && runFinished(run) {
+			writeEvent(w, "", "end", run)
+			rc.Flush()
+			return
+		}
+
+		if len(logs) > 0 {
+			lastWrite = time.Now()
+		} else if time.Since(lastWrite) >= runLogKeepAlive {
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			lastWrite = time.Now()
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		// Keep reading without waiting while there is a backlog
+		if len(logs) == runLogPageSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeEvent writes a server-sent event with data encoded as JSON
+func writeEvent(w http.ResponseWriter, id, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
+
+// runFinished reports whether a run has reached a final status
+func runFinished(run *types.Run) bool {
+	switch run.Status {
+	case types.RunStatusScheduled, types.RunStatusClaimed, types.RunStatusRunning:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/common"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/db/store"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/scheduler"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// SchedulerHandler handles scheduler status HTTP requests
+type SchedulerHandler struct {
+	leaseStore *store.LeaseStore
+	logger     *zap.Logger
+}
+
+// NewSchedulerHandler creates a new scheduler handler
+func NewSchedulerHandler(leaseStore *store.LeaseStore, logger *zap.Logger) *SchedulerHandler {
+	return &SchedulerHandler{
+		leaseStore: leaseStore,
+		logger:     logger,
+	}
+}
+
+// leaderResponse describes the current scheduler leader
+type leaderResponse struct {
+	*types.Lease
+	Active bool `json:"active"` // False when the lease has expired without being taken over
+}
+
+// GetLeader handles GET /api/v1/scheduler/leader
+func (h *SchedulerHandler) GetLeader(w http.ResponseWriter, r *http.Request) {
+	lease, err := h.leaseStore.GetLease(r.Context(), scheduler.LeaderLeaseName)
+	if err != nil {
+		if err.Error() == "lease not found" {
+			common.WriteNotFoundError(w, "Leader", h.logger)
+		} else {
+			h.logger.Error("Failed to get scheduler leader", zap.Error(err))
+			common.WriteInternalError(w, h.logger)
+		}
+		return
+	}
+
+	response := leaderResponse{
+		Lease:  lease,
+		Active: lease.ExpiresAt.After(time.Now()),
+	}
+
+	common.WriteJSON(w, http.StatusOK, response, h.logger)
+}
@@ -6,15 +6,16 @@ import (
 	"fmt"
 	"net/http"
 	"slices"
-	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
 	"github.com/Franklyne-kibet/aster-scheduler/internal/api/handlers"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/common"
 	"github.com/Franklyne-kibet/aster-scheduler/internal/config"
 	"github.com/Franklyne-kibet/aster-scheduler/internal/db/store"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/executor"
 )
 
 // Server represents the HTTP API server
@@ -24,10 +25,12 @@ type Server struct {
 }
 
 // NewServer creates a new API server
-func NewServer(cfg *config.Config, jobStore *store.JobStore, runStore *store.RunStore, logger *zap.Logger) *Server {
+func NewServer(cfg *config.Config, jobStore *store.JobStore, runStore *store.RunStore, runLogStore *store.RunLogStore, leaseStore *store.LeaseStore, backfillStore *store.BackfillStore, executors *executor.Registry, logger *zap.Logger) *Server {
 	// Create handlers
-	jobHandler := handlers.NewJobHandler(jobStore, logger)
-	runHandler := handlers.NewRunHandler(runStore, logger)
+	jobHandler := handlers.NewJobHandler(jobStore, runStore, executors, logger)
+	runHandler := handlers.NewRunHandler(runStore, runLogStore, logger)
+	schedulerHandler := handlers.NewSchedulerHandler(leaseStore, logger)
+	backfillHandler := handlers.NewBackfillHandler(jobStore, backfillStore, logger)
 
 	// Create router
 	router := mux.NewRouter()
@@ -46,10 +49,22 @@ func NewServer(cfg *config.Config, jobStore *store.JobStore, runStore *store.Run
 	apiRouter.HandleFunc("/jobs/{id}", jobHandler.GetJob).Methods("GET")
 	apiRouter.HandleFunc("/jobs/{id}", jobHandler.UpdateJob).Methods("PUT")
 	apiRouter.HandleFunc("/jobs/{id}", jobHandler.DeleteJob).Methods("DELETE")
+	apiRouter.HandleFunc("/jobs/{id}/next-runs", jobHandler.GetJobNextRuns).Methods("GET")
+	apiRouter.HandleFunc("/jobs/{id}/trigger", jobHandler.TriggerJob).Methods("POST")
+	apiRouter.HandleFunc("/jobs/{id}/backfill", backfillHandler.CreateBackfill).Methods("POST")
+
+	// Backfill routes
+	apiRouter.HandleFunc("/backfills/{id}", backfillHandler.GetBackfill).Methods("GET")
 
 	// Run routes
 	apiRouter.HandleFunc("/runs", runHandler.ListRuns).Methods("GET")
 	apiRouter.HandleFunc("/runs/{id}", runHandler.GetRun).Methods("GET")
+	apiRouter.HandleFunc("/runs/{id}/attempts", runHandler.GetRunAttempts).Methods("GET")
+	apiRouter.HandleFunc("/runs/{id}/cancel", runHandler.CancelRun).Methods("POST")
+	apiRouter.HandleFunc("/runs/{id}/logs", runHandler.GetRunLogs).Methods("GET")
+
+	// Scheduler routes
+	apiRouter.HandleFunc("/scheduler/leader", schedulerHandler.GetLeader).Methods("GET")
 
 	// Health check
 	router.HandleFunc("/health", healthHandler).Methods("GET")
@@ -126,7 +141,7 @@ func loggingMiddleware(logger *zap.Logger) mux.MiddlewareFunc {
 				zap.Int("status", ww.statusCode),
 				zap.Duration("duration", time.Since(start)),
 				zap.String("user_agent", r.UserAgent()),
-				zap.String("remote_addr", getClientIP(r)))
+				zap.String("remote_addr", common.ClientIP(r)))
 		})
 	}
 }
@@ -163,25 +178,6 @@ func isOriginAllowed(origin string, allowedOrigins []string) bool {
 	return slices.Contains(allowedOrigins, origin)
 }
 
-// getClientIP extracts client IP, handling proxies
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
-	xff := r.Header.Get("X-Forwarded-For")
-	if xff != "" {
-		// Take first IP in case of multiple
-		return strings.Split(xff, ",")[0]
-	}
-
-	// Check X-Real-IP header
-	xri := r.Header.Get("X-Real-IP")
-	if xri != "" {
-		return xri
-	}
-
-	// Fall back to RemoteAddr
-	return r.RemoteAddr
-}
-
 // responseWriter wraps http.ResponseWriter to capture status code
 type responseWriter struct {
 	http.ResponseWriter
@@ -193,6 +189,12 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer, which
+// streaming responses use to flush
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // healthHandler provides a simple health check endpoint
 func healthHandler(w http.ResponseWriter, r *http.Request) {
 	response := map[string]any{
@@ -0,0 +1,25 @@
+package common
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ClientIP extracts client IP, handling proxies
+func ClientIP(r *http.Request) string {
+	// Check X-Forwarded-For header
+	xff := r.Header.Get("X-Forwarded-For")
+	if xff != "" {
+		// Take first IP in case of multiple
+		return strings.TrimSpace(strings.Split(xff, ",")[0])
+	}
+
+	// Check X-Real-IP header
+	xri := r.Header.Get("X-Real-IP")
+	if xri != "" {
+		return xri
+	}
+
+	// Fall back to RemoteAddr
+	return r.RemoteAddr
+}
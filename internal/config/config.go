@@ -4,11 +4,15 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// defaultJobEnvDenylist keeps the worker's own credentials away from jobs
+var defaultJobEnvDenylist = []string{"POSTGRES_*", "DATABASE_URL", "SQL_CONNECTION_*"}
+
 type Config struct {
 	// Database connection string
 	DatabaseURL string
@@ -25,11 +29,34 @@ type Config struct {
 	// Jobs running at once
 	WorkerPoolSize int
 
+	// Patterns of worker environment variables that commands may inherit
+	// (empty allows all) and that they never inherit
+	JobEnvAllowlist []string
+	JobEnvDenylist  []string
+
+	// Delegated cgroup v2 directory in which runs with resource limits get a
+	// group of their own; empty limits runs with rlimits alone
+	JobCgroupPath string
+
+	// Users and groups that command jobs may run as; jobs can't switch
+	// accounts unless they are listed
+	JobRunAsUsers  []string
+	JobRunAsGroups []string
+
 	// Logging level (debug, info, warn, error)
 	LogLevel string
 
 	// Scheduler leader election TTL
 	LeaderElectionTTL time.Duration
+
+	// How often workers heartbeat their runs, and how long the scheduler
+	// waits without one before failing a run as orphaned
+	RunHeartbeatInterval time.Duration
+	RunHeartbeatTimeout  time.Duration
+
+	// How long workers let in-flight runs finish, and the scheduler its
+	// current tick, after being asked to stop
+	ShutdownGracePeriod time.Duration
 }
 
 // Load reads configuration from environment variables
@@ -45,21 +72,44 @@ func Load() (*Config, error) {
 
 	// Create a new Config with default values
 	cfg := &Config{
-		DatabaseURL:       dbURL,
-		APIPort:           getEnvInt("API_PORT", 8080),
-		AllowedOrigins:    getEnvStringSlice("ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
-		ReadTimeout:       getEnvDuration("READ_TIMEOUT", 15*time.Second),
-		WriteTimeout:      getEnvDuration("WRITE_TIMEOUT", 15*time.Second),
-		IdleTimeout:       getEnvDuration("IDLE_TIMEOUT", 60*time.Second),
-		WorkerPoolSize:    getEnvInt("WORKER_POOL_SIZE", 5),
-		LogLevel:          getEnv("LOG_LEVEL", "info"),
-		LeaderElectionTTL: getEnvDuration("LEADER_ELECTION_TTL", 30*time.Second),
+		DatabaseURL:          dbURL,
+		APIPort:              getEnvInt("API_PORT", 8080),
+		AllowedOrigins:       getEnvStringSlice("ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
+		ReadTimeout:          getEnvDuration("READ_TIMEOUT", 15*time.Second),
+		WriteTimeout:         getEnvDuration("WRITE_TIMEOUT", 15*time.Second),
+		IdleTimeout:          getEnvDuration("IDLE_TIMEOUT", 60*time.Second),
+		WorkerPoolSize:       getEnvInt("WORKER_POOL_SIZE", 5),
+		JobEnvAllowlist:      getEnvStringSlice("JOB_ENV_ALLOWLIST", nil),
+		JobEnvDenylist:       getEnvStringSlice("JOB_ENV_DENYLIST", defaultJobEnvDenylist),
+		JobCgroupPath:        getEnv("JOB_CGROUP_PATH", ""),
+		JobRunAsUsers:        getEnvStringSlice("JOB_RUN_AS_USERS", nil),
+		JobRunAsGroups:       getEnvStringSlice("JOB_RUN_AS_GROUPS", nil),
+		LogLevel:             getEnv("LOG_LEVEL", "info"),
+		LeaderElectionTTL:    getEnvDuration("LEADER_ELECTION_TTL", 30*time.Second),
+		RunHeartbeatInterval: getEnvDuration("RUN_HEARTBEAT_INTERVAL", 10*time.Second),
+		RunHeartbeatTimeout:  getEnvDuration("RUN_HEARTBEAT_TIMEOUT", 60*time.Second),
+		ShutdownGracePeriod:  getEnvDuration("SHUTDOWN_GRACE_PERIOD", 30*time.Second),
 	}
 	// Validate required fields
 	if cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL is required")
 	}
 
+	// A run must be able to miss a heartbeat without being reaped
+	if cfg.RunHeartbeatInterval <= 0 || cfg.RunHeartbeatTimeout < 2*cfg.RunHeartbeatInterval {
+		return nil, fmt.Errorf("RUN_HEARTBEAT_TIMEOUT must be at least twice RUN_HEARTBEAT_INTERVAL")
+	}
+
+	for _, pattern := range append(cfg.JobEnvAllowlist, cfg.JobEnvDenylist...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid environment variable pattern '%s' in JOB_ENV_ALLOWLIST or JOB_ENV_DENYLIST", pattern)
+		}
+	}
+
+	if cfg.JobCgroupPath != "" && !path.IsAbs(cfg.JobCgroupPath) {
+		return nil, fmt.Errorf("JOB_CGROUP_PATH must be an absolute path")
+	}
+
 	return cfg, nil
 }
 
@@ -55,3 +55,29 @@ func TestLoadWithEnvironmentVariables(t *testing.T) {
 		t.Errorf("Expected LogLevel debug, got %s", cfg.LogLevel)
 	}
 }
+
+// TestLoadRejectsShortHeartbeatTimeout tests that runs can't be reaped between heartbeats
+func TestLoadRejectsShortHeartbeatTimeout(t *testing.T) {
+	os.Setenv("RUN_HEARTBEAT_INTERVAL", "30s")
+	os.Setenv("RUN_HEARTBEAT_TIMEOUT", "45s")
+
+	defer func() {
+		os.Unsetenv("RUN_HEARTBEAT_INTERVAL")
+		os.Unsetenv("RUN_HEARTBEAT_TIMEOUT")
+	}()
+
+	if _, err := Load(); err == nil {
+		t.Error("Expected an error for a heartbeat timeout shorter than two intervals")
+	}
+}
+
+// TestLoadRejectsBadEnvPattern tests that env allowlist patterns are checked at startup
+func TestLoadRejectsBadEnvPattern(t *testing.T) {
+	os.Setenv("JOB_ENV_ALLOWLIST", "APP_*,[")
+
+	defer os.Unsetenv("JOB_ENV_ALLOWLIST")
+
+	if _, err := Load(); err == nil {
+		t.Error("Expected an error for a malformed env pattern")
+	}
+}
@@ -0,0 +1,6 @@
+-- Track which worker claimed a run and when
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(255);
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMP WITH TIME ZONE;
+
+-- Claiming scans scheduled runs in scheduled_at order
+CREATE INDEX IF NOT EXISTS idx_runs_scheduled_queue ON runs(scheduled_at) WHERE status = 'scheduled';
@@ -0,0 +1,7 @@
+-- Per-job retry backoff policy
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS retry_policy JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- Link each retry run to the run it retries
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS retry_of UUID REFERENCES runs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_runs_retry_of ON runs(retry_of);
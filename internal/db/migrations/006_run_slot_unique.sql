@@ -0,0 +1,42 @@
+-- A job's schedule slot can only produce one run per attempt
+-- Skipped once 009 has replaced the constraint with a partial index, since
+-- backfills may repeat a slot after that
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'runs_job_slot_attempt_key'
+            AND conrelid = 'runs'::regclass
+    ) AND to_regclass('idx_runs_schedule_slot_attempt') IS NULL THEN
+        -- The scheduler could create a slot twice before this constraint
+        -- existed. Keep one run per slot and attempt, preferring one that
+        -- started, and point retries of the others at it
+        CREATE TEMP TABLE duplicate_slot_runs ON COMMIT DROP AS
+        SELECT id, keep_id
+        FROM (
+            SELECT id,
+                first_value(id) OVER slot AS keep_id,
+                row_number() OVER slot AS n
+            FROM runs
+            WINDOW slot AS (
+                PARTITION BY job_id, scheduled_at, attempt_num
+                ORDER BY started_at IS NULL, created_at, id
+            )
+        ) ranked
+        WHERE n > 1;
+
+        UPDATE runs
+        SET retry_of = d.keep_id
+        FROM duplicate_slot_runs d
+        WHERE runs.retry_of = d.id;
+
+        DELETE FROM runs
+        USING duplicate_slot_runs d
+        WHERE runs.id = d.id;
+
+        ALTER TABLE runs
+            ADD CONSTRAINT runs_job_slot_attempt_key UNIQUE (job_id, scheduled_at, attempt_num);
+    END IF;
+END;
+$$;
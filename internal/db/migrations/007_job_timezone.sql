@@ -0,0 +1,2 @@
+-- Time zone each job's cron expression is evaluated in
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,2 @@
+-- Per-job policy for catching up schedule slots missed while no scheduler ran
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS misfire_policy JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,26 @@
+-- Backfills re-run a job for every cron slot in a past time range
+CREATE TABLE IF NOT EXISTS backfills (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    parallelism INTEGER NOT NULL DEFAULT 1 CHECK (parallelism > 0),
+    run_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_backfills_job_id ON backfills(job_id);
+
+-- Record what created each run
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS trigger VARCHAR(20) NOT NULL DEFAULT 'schedule';
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS backfill_id UUID REFERENCES backfills(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_runs_backfill_id ON runs(backfill_id);
+
+-- Backfilled slots may overlap slots the scheduler already ran, so only
+-- scheduler-created runs are unique per slot and attempt
+ALTER TABLE runs DROP CONSTRAINT IF EXISTS runs_job_slot_attempt_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_runs_schedule_slot_attempt
+    ON runs(job_id, scheduled_at, attempt_num)
+    WHERE trigger = 'schedule';
@@ -0,0 +1,10 @@
+-- Per-job policy for overlapping runs
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS concurrency_policy VARCHAR(20) NOT NULL DEFAULT 'allow';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS max_concurrent_runs INTEGER NOT NULL DEFAULT 0;
+
+-- Set when a run should stop, for example because a newer run replaces it
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS cancel_requested_at TIMESTAMP WITH TIME ZONE;
+
+-- Claims count each job's active runs
+CREATE INDEX IF NOT EXISTS idx_runs_job_active ON runs(job_id)
+    WHERE status IN ('claimed', 'running');
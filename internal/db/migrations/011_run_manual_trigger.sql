@@ -0,0 +1,3 @@
+-- Who created a manually triggered run, and the job settings it overrides
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS triggered_by VARCHAR(255);
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS overrides JSONB;
@@ -0,0 +1,10 @@
+-- Refreshed by the owning worker (claimed_by) while a run is in flight
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP WITH TIME ZONE;
+
+-- Runs already in flight count from when they were last known to be alive
+UPDATE runs SET heartbeat_at = COALESCE(started_at, claimed_at, updated_at)
+    WHERE status IN ('claimed', 'running') AND heartbeat_at IS NULL;
+
+-- The reaper looks for in-flight runs whose heartbeat has expired
+CREATE INDEX IF NOT EXISTS idx_runs_active_heartbeat ON runs(heartbeat_at)
+    WHERE status IN ('claimed', 'running');
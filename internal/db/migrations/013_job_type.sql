@@ -0,0 +1,3 @@
+-- Executor each job runs with, and settings specific to that executor
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS type VARCHAR(50) NOT NULL DEFAULT 'command';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS config JSONB NOT NULL DEFAULT '{}';
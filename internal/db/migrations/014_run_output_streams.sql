@@ -0,0 +1,9 @@
+-- Each stream is kept separately next to the interleaved output
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS stdout TEXT NOT NULL DEFAULT '';
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS stderr TEXT NOT NULL DEFAULT '';
+
+-- NULL until the process exits by itself, and for runs killed by a signal
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS exit_code INTEGER;
+
+-- Set when the middle of long output was dropped
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS output_truncated BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,11 @@
+-- Output chunks written by workers while a run is in flight, in order of id
+CREATE TABLE IF NOT EXISTS run_logs (
+    id BIGSERIAL PRIMARY KEY,
+    run_id UUID NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+    stream VARCHAR(10) NOT NULL,
+    data TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Readers page through a run's logs by id
+CREATE INDEX IF NOT EXISTS idx_run_logs_run_id ON run_logs(run_id, id);
@@ -0,0 +1,3 @@
+-- Signal that ended a run's process, such as SIGTERM or SIGKILL; NULL for
+-- processes that exited by themselves
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS term_signal VARCHAR(20);
@@ -0,0 +1,5 @@
+-- JSON document a job reported about its run, such as {"rows": 1234}
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS result JSONB;
+
+-- Supports filtering runs on their result with @>
+CREATE INDEX IF NOT EXISTS idx_runs_result ON runs USING GIN (result jsonb_path_ops);
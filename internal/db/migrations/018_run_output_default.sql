@@ -0,0 +1,3 @@
+-- Runs inserted without an output read back as an empty string
+UPDATE runs SET output = '' WHERE output IS NULL;
+ALTER TABLE runs ALTER COLUMN output SET DEFAULT '';
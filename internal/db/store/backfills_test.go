@@ -0,0 +1,148 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+func TestBackfillStore_ClaimRespectsParallelism(t *testing.T) {
+	jobStore, runStore := setupRunTestDB(t)
+	if jobStore == nil {
+		return // Test was skipped
+	}
+
+	ctx := context.Background()
+	job := createTestJob(t, jobStore, "test_backfill_parallelism")
+	backfillStore := NewBackfillStore(runStore.db)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var runs []*types.Run
+	for i := range 4 {
+		runs = append(runs, &types.Run{
+			Status:      types.RunStatusScheduled,
+			AttemptNum:  1,
+			ScheduledAt: start.AddDate(0, 0, i),
+		})
+	}
+
+	backfill := &types.Backfill{JobID: job.ID, StartAt: start, EndAt: start.AddDate(0, 0, 3), Parallelism: 2}
+	if err := backfillStore.CreateBackfill(ctx, backfill, runs); err != nil {
+		t.Fatalf("Failed to create backfill: %v", err)
+	}
+
+	stored, err := backfillStore.GetBackfill(ctx, backfill.ID)
+	if err != nil {
+		t.Fatalf("Failed to get backfill: %v", err)
+	}
+	if stored.RunCount != 4 {
+		t.Errorf("Expected run_count 4, got %d", stored.RunCount)
+	}
+
+	// Backfilled slots may repeat slots the scheduler already ran
+	scheduled := &types.Run{JobID: job.ID, Status: types.RunStatusSucceeded, AttemptNum: 1, ScheduledAt: start}
+	if created, err := runStore.CreateRunOnce(ctx, scheduled); err != nil || !created {
+		t.Fatalf("Expected scheduled run for a backfilled slot to be created, got %v, %v", created, err)
+	}
+
+	listed, err := runStore.ListRuns(ctx, RunFilter{BackfillID: &backfill.ID}, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to list backfill runs: %v", err)
+	}
+	if len(listed) != 4 {
+		t.Fatalf("Expected 4 backfill runs, got %d", len(listed))
+	}
+	for _, run := range listed {
+		if run.Trigger != types.RunTriggerBackfill {
+			t.Errorf("Expected trigger %s, got %s", types.RunTriggerBackfill, run.Trigger)
+		}
+	}
+
+	// countClaimed claims everything available and counts this backfill's runs
+	countClaimed := func(worker string) []*types.Run {
+		t.Helper()
+		claimed, err := runStore.ClaimRuns(ctx, worker, 10)
+		if err != nil {
+			t.Fatalf("Failed to claim runs: %v", err)
+		}
+
+		var ours []*types.Run
+		for _, run := range claimed {
+			if run.BackfillID != nil && *run.BackfillID == backfill.ID {
+				ours = append(ours, run)
+			}
+		}
+		return ours
+	}
+
+	first := countClaimed("test-worker-a")
+	if len(first) != 2 {
+		t.Fatalf("Expected 2 runs claimed with parallelism 2, got %d", len(first))
+	}
+	if !first[0].ScheduledAt.Before(runs[2].ScheduledAt) || !first[1].ScheduledAt.Before(runs[2].ScheduledAt) {
+		t.Error("Expected the earliest slots to be claimed first")
+	}
+
+	if again := countClaimed("test-worker-b"); len(again) != 0 {
+		t.Errorf("Expected no runs claimed while the backfill is at its limit, got %d", len(again))
+	}
+
+	// Finishing one run frees a slot for the next
+	if err := runStore.MarkRunFinished(ctx, first[0].ID, types.RunStatusSucceeded, types.RunOutput{}, nil); err != nil {
+		t.Fatalf("Failed to finish run: %v", err)
+	}
+	if next := countClaimed("test-worker-b"); len(next) != 1 {
+		t.Errorf("Expected 1 run claimed after one finished, got %d", len(next))
+	}
+}
+
+func TestBackfillStore_CreateBackfill_RunsReadBack(t *testing.T) {
+	jobStore, runStore := setupRunTestDB(t)
+	if jobStore == nil {
+		return // Test was skipped
+	}
+
+	ctx := context.Background()
+	job := createTestJob(t, jobStore, "test_backfill_read_back")
+	backfillStore := NewBackfillStore(runStore.db)
+
+	start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	runs := []*types.Run{
+		{Status: types.RunStatusScheduled, AttemptNum: 1, ScheduledAt: start},
+		{Status: types.RunStatusScheduled, AttemptNum: 1, ScheduledAt: start.AddDate(0, 0, 1)},
+	}
+
+	backfill := &types.Backfill{JobID: job.ID, StartAt: start, EndAt: start.AddDate(0, 0, 1), Parallelism: 2}
+	if err := backfillStore.CreateBackfill(ctx, backfill, runs); err != nil {
+		t.Fatalf("Failed to create backfill: %v", err)
+	}
+
+	// Every column scanRun reads must be readable for a fresh backfill run
+	got, err := runStore.GetRun(ctx, runs[0].ID)
+	if err != nil {
+		t.Fatalf("Failed to get backfill run: %v", err)
+	}
+	if got.Output != "" || got.BackfillID == nil || *got.BackfillID != backfill.ID {
+		t.Errorf("Expected an empty output and backfill %s, got %q and %v", backfill.ID, got.Output, got.BackfillID)
+	}
+
+	if _, err := runStore.GetRunAttempts(ctx, runs[1].ID); err != nil {
+		t.Fatalf("Failed to get backfill run attempts: %v", err)
+	}
+
+	claimed, err := runStore.ClaimRuns(ctx, "test-worker-read-back", 10)
+	if err != nil {
+		t.Fatalf("Failed to claim runs: %v", err)
+	}
+	var ours int
+	for _, run := range claimed {
+		if run.BackfillID != nil && *run.BackfillID == backfill.ID {
+			ours++
+		}
+	}
+	if ours != 2 {
+		t.Errorf("Expected 2 backfill runs claimed, got %d", ours)
+	}
+}
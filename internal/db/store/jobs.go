@@ -8,19 +8,119 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
 )
 
+// jobColumns lists the columns read for every job query, in scanJob order
+const jobColumns = `id, name, description, cron_expr, timezone, type, config, command, args, env,
+	status, max_retries, retry_policy, misfire_policy, concurrency_policy, max_concurrent_runs,
+	timeout, created_at, updated_at, next_run_at`
+
 // JobStore handles all job-related database operations
 type JobStore struct {
-	pool *pgxpool.Pool
+	db DBTX
 }
 
 // NewJobStore creates a new JobStore
-func NewJobStore(pool *pgxpool.Pool) *JobStore {
-	return &JobStore{pool: pool}
+func NewJobStore(db DBTX) *JobStore {
+	return &JobStore{db: db}
+}
+
+// WithTx returns a copy of the store that runs its queries in tx
+func (s *JobStore) WithTx(tx pgx.Tx) *JobStore {
+	return &JobStore{db: tx}
+}
+
+// scanJob reads a single job row selected with jobColumns
+func scanJob(row pgx.Row) (*types.Job, error) {
+	var job types.Job
+	var configJSON, argsJSON, envJSON, retryPolicyJSON, misfirePolicyJSON []byte
+	var timeout *time.Duration // Stored as an INTERVAL
+
+	err := row.Scan(
+		&job.ID,
+		&job.Name,
+		&job.Description,
+		&job.CronExpr,
+		&job.Timezone,
+		&job.Type,
+		&configJSON,
+		&job.Command,
+		&argsJSON, // Scan JSON as bytes
+		&envJSON,  // Scan JSON as bytes
+		&job.Status,
+		&job.MaxRetries,
+		&retryPolicyJSON,
+		&misfirePolicyJSON,
+		&job.ConcurrencyPolicy,
+		&job.MaxConcurrentRuns,
+		&timeout,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.NextRunAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	job.Timeout = (*types.Duration)(timeout)
+
+	// An empty config is stored as {} and read back as none
+	if string(configJSON) != "{}" {
+		job.Config = configJSON
+	}
+
+	// Convert JSON back to Go types
+	if err := json.Unmarshal(argsJSON, &job.Args); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal args: %w", err)
+	}
+
+	if err := json.Unmarshal(envJSON, &job.Env); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal env: %w", err)
+	}
+
+	if err := json.Unmarshal(retryPolicyJSON, &job.RetryPolicy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal retry policy: %w", err)
+	}
+
+	if err := json.Unmarshal(misfirePolicyJSON, &job.MisfirePolicy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal misfire policy: %w", err)
+	}
+
+	return &job, nil
+}
+
+// collectJobs scans every row returned by a job query
+func collectJobs(rows pgx.Rows) ([]*types.Job, error) {
+	defer rows.Close() // close rows when done
+
+	var jobs []*types.Job
+
+	// Iterate through all rows
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	// Check for errors that occurred during iteration
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", rows.Err())
+	}
+
+	return jobs, nil
+}
+
+// marshalConfig returns a job's type-specific config for storage, using {}
+// when there is none
+func marshalConfig(config json.RawMessage) []byte {
+	if len(config) == 0 || string(config) == "null" {
+		return []byte("{}")
+	}
+	return config
 }
 
 // CreateJob inserts a new job into the database
@@ -36,10 +136,21 @@ func (s *JobStore) CreateJob(ctx context.Context, job *types.Job) error {
 		return fmt.Errorf("failed to marshal env: %w", err)
 	}
 
+	retryPolicyJSON, err := json.Marshal(job.RetryPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal retry policy: %w", err)
+	}
+
+	misfirePolicyJSON, err := json.Marshal(job.MisfirePolicy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal misfire policy: %w", err)
+	}
+
 	// SQL query to insert job
 	query := `
-		INSERT INTO jobs (id, name, description, cron_expr, command, args, env, status, max_retries, timeout)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO jobs (id, name, description, cron_expr, command, args, env, status, max_retries, retry_policy, timeout, timezone, misfire_policy,
+			concurrency_policy, max_concurrent_runs, type, config)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`
 
 	// Generate UUID if not provided
@@ -48,7 +159,7 @@ func (s *JobStore) CreateJob(ctx context.Context, job *types.Job) error {
 	}
 
 	// Execute the query
-	_, err = s.pool.Exec(ctx, query,
+	_, err = s.db.Exec(ctx, query,
 		job.ID,
 		job.Name,
 		job.Description,
@@ -58,7 +169,14 @@ func (s *JobStore) CreateJob(ctx context.Context, job *types.Job) error {
 		envJSON,
 		job.Status,
 		job.MaxRetries,
-		job.Timeout,
+		retryPolicyJSON,
+		(*time.Duration)(job.Timeout),
+		job.Timezone,
+		misfirePolicyJSON,
+		job.ConcurrencyPolicy,
+		job.MaxConcurrentRuns,
+		job.Type,
+		marshalConfig(job.Config),
 	)
 
 	if err != nil {
@@ -69,32 +187,10 @@ func (s *JobStore) CreateJob(ctx context.Context, job *types.Job) error {
 
 // GetJob retrieves a job by ID
 func (s *JobStore) GetJob(ctx context.Context, id uuid.UUID) (*types.Job, error) {
-	query := `
-		SELECT id, name, description, cron_expr, command, args, env, 
-		status, max_retries, timeout, created_at, updated_at, next_run_at
-		FROM jobs 
-		WHERE id = $1
-	`
-
-	var job types.Job
-	var argsJSON, envJSON []byte
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE id = $1`
 
 	// QueryRow returns at most one row
-	err := s.pool.QueryRow(ctx, query, id).Scan(
-		&job.ID,
-		&job.Name,
-		&job.Description,
-		&job.CronExpr,
-		&job.Command,
-		&argsJSON, // Scan JSON as bytes
-		&envJSON,  // Scan JSON as bytes
-		&job.Status,
-		&job.MaxRetries,
-		&job.Timeout,
-		&job.CreatedAt,
-		&job.UpdatedAt,
-		&job.NextRunAt,
-	)
+	job, err := scanJob(s.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			// No job found with this ID
@@ -103,83 +199,29 @@ func (s *JobStore) GetJob(ctx context.Context, id uuid.UUID) (*types.Job, error)
 		return nil, fmt.Errorf("failed to get job: %w", err)
 	}
 
-	// Convert JSON back to Go types
-	if err := json.Unmarshal(argsJSON, &job.Args); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal args: %w", err)
-	}
-
-	if err := json.Unmarshal(envJSON, &job.Env); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal env: %w", err)
-	}
-
-	return &job, nil
+	return job, nil
 }
 
 // ListJobs returns a paginated list of jobs
 func (s *JobStore) ListJobs(ctx context.Context, limit, offset int) ([]*types.Job, error) {
 	query := `
-		SELECT id, name, description, cron_expr, command, args, env,
-		status, max_retries, timeout, created_at, updated_at, next_run_at
+		SELECT ` + jobColumns + `
 		FROM jobs
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
 
 	// Query returns multiple rows
-	rows, err := s.pool.Query(ctx, query, limit, offset)
+	rows, err := s.db.Query(ctx, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query jobs: %w", err)
 	}
-	defer rows.Close() // close rows when done
-
-	var jobs []*types.Job
-
-	// Iterate through all rows
-	for rows.Next() {
-		var job types.Job
-		var argsJSON, envJSON []byte
-
-		err := rows.Scan(
-			&job.ID,
-			&job.Name,
-			&job.Description,
-			&job.CronExpr,
-			&job.Command,
-			&argsJSON,
-			&envJSON,
-			&job.Status,
-			&job.MaxRetries,
-			&job.Timeout,
-			&job.CreatedAt,
-			&job.UpdatedAt,
-			&job.NextRunAt,
-		)
-
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan job: %w", err)
-		}
-
-		// Convert JSON to Go types
-		if err := json.Unmarshal(argsJSON, &job.Args); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal args: %w", err)
-		}
 
-		if err := json.Unmarshal(envJSON, &job.Env); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal env: %w", err)
-		}
-
-		jobs = append(jobs, &job)
-	}
-
-	// Check for errors that occurred during iteration
-	if rows.Err() != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", rows.Err())
-	}
-
-	return jobs, nil
+	return collectJobs(rows)
 }
 
-// UpdateJob updates existing job
+// UpdateJob updates existing job. next_run_at is only changed when the job
+// has one set, so an update can't undo the scheduler advancing it.
 func (s *JobStore) UpdateJob(ctx context.Context, job *types.Job) error {
 	argsJSON, err := json.Marshal(job.Args)
 	if err != nil {
@@ -191,15 +233,28 @@ func (s *JobStore) UpdateJob(ctx context.Context, job *types.Job) error {
 		return fmt.Errorf("failed to marshal env: %w", err)
 	}
 
+	retryPolicyJSON, err := json.Marshal(job.RetryPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal retry policy: %w", err)
+	}
+
+	misfirePolicyJSON, err := json.Marshal(job.MisfirePolicy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal misfire policy: %w", err)
+	}
+
 	query := `
-		UPDATE jobs 
+		UPDATE jobs
 		SET name = $2, description = $3, cron_expr = $4, command = $5,
-		args = $6, env = $7, status = $8, max_retries = $9, 
-		timeout = $10, updated_at = NOW()
+		args = $6, env = $7, status = $8, max_retries = $9,
+		retry_policy = $10, timeout = $11, timezone = $12,
+		misfire_policy = $13, concurrency_policy = $14, max_concurrent_runs = $15,
+		type = $16, config = $17, next_run_at = COALESCE($18, next_run_at),
+		updated_at = NOW()
 		WHERE id = $1
 	`
 
-	result, err := s.pool.Exec(ctx, query,
+	result, err := s.db.Exec(ctx, query,
 		job.ID,
 		job.Name,
 		job.Description,
@@ -209,7 +264,15 @@ func (s *JobStore) UpdateJob(ctx context.Context, job *types.Job) error {
 		envJSON,
 		job.Status,
 		job.MaxRetries,
-		job.Timeout,
+		retryPolicyJSON,
+		(*time.Duration)(job.Timeout),
+		job.Timezone,
+		misfirePolicyJSON,
+		job.ConcurrencyPolicy,
+		job.MaxConcurrentRuns,
+		job.Type,
+		marshalConfig(job.Config),
+		job.NextRunAt,
 	)
 
 	if err != nil {
@@ -228,7 +291,7 @@ func (s *JobStore) UpdateJob(ctx context.Context, job *types.Job) error {
 func (s *JobStore) DeleteJob(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM jobs WHERE id = $1`
 
-	result, err := s.pool.Exec(ctx, query, id)
+	result, err := s.db.Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete job: %w", err)
 	}
@@ -242,74 +305,30 @@ func (s *JobStore) DeleteJob(ctx context.Context, id uuid.UUID) error {
 // GetActiveJobsDue returns active jobs that should run before the given time
 func (s *JobStore) GetActiveJobsDue(ctx context.Context, before time.Time) ([]*types.Job, error) {
 	query := `
-		SELECT id, name, description, cron_expr, command, args, env,
-		  status, max_retries, timeout, created_at, updated_at, next_run_at
+		SELECT ` + jobColumns + `
 		FROM jobs
-		WHERE status = $1 
+		WHERE status = $1
 		  AND (next_run_at IS NULL OR next_run_at <= $2)
 		ORDER BY next_run_at ASC
 	`
 
-	rows, err := s.pool.Query(ctx, query, types.JobStatusActive, before)
+	rows, err := s.db.Query(ctx, query, types.JobStatusActive, before)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query due jobs: %w", err)
 	}
-	defer rows.Close()
-
-	var jobs []*types.Job
 
-	for rows.Next() {
-		var job types.Job
-		var argsJSON, envJSON []byte
-
-		err := rows.Scan(
-			&job.ID,
-			&job.Name,
-			&job.Description,
-			&job.CronExpr,
-			&job.Command,
-			&argsJSON,
-			&envJSON,
-			&job.Status,
-			&job.MaxRetries,
-			&job.Timeout,
-			&job.CreatedAt,
-			&job.UpdatedAt,
-			&job.NextRunAt,
-		)
-
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan job: %w", err)
-		}
-
-		// Convert JSON to GO types
-		if err := json.Unmarshal(argsJSON, &job.Args); err != nil {
-			return nil, fmt.Errorf("faiiled to unmarshal args: %w", err)
-		}
-
-		if err := json.Unmarshal(envJSON, &job.Env); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal env: %w", err)
-		}
-
-		jobs = append(jobs, &job)
-	}
-
-	if rows.Err() != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", rows.Err())
-	}
-
-	return jobs, nil
+	return collectJobs(rows)
 }
 
 // UpdateJobNextRunAt updates when a job should next run
 func (s *JobStore) UpdateJobNextRunAt(ctx context.Context, jobID uuid.UUID, nextRunAt *time.Time) error {
 	query := `
-		UPDATE jobs 
+		UPDATE jobs
 		SET next_run_at = $2, updated_at = NOW()
 		WHERE id = $1
 	`
 
-	result, err := s.pool.Exec(ctx, query, jobID, nextRunAt)
+	result, err := s.db.Exec(ctx, query, jobID, nextRunAt)
 	if err != nil {
 		return fmt.Errorf("failed to update job next run time: %w", err)
 	}
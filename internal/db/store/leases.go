@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// LeaseStore handles lease-based leader election
+type LeaseStore struct {
+	db DBTX
+}
+
+// NewLeaseStore creates a new lease store
+func NewLeaseStore(db DBTX) *LeaseStore {
+	return &LeaseStore{db: db}
+}
+
+// TryAcquire takes or renews the named lease for holder. It succeeds when the
+// lease is free, expired or already held by holder, and reports false when
+// another holder's lease is still valid. Expiry uses the database clock so
+// instances with skewed clocks agree on who holds the lease.
+func (s *LeaseStore) TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (*types.Lease, bool, error) {
+	query := `
+		INSERT INTO leases (name, holder, acquired_at, renewed_at, expires_at)
+		VALUES ($1, $2, NOW(), NOW(), NOW() + $3::interval)
+		ON CONFLICT (name) DO UPDATE
+		SET holder = EXCLUDED.holder,
+		    acquired_at = CASE WHEN leases.holder = EXCLUDED.holder
+		                       THEN leases.acquired_at ELSE NOW() END,
+		    renewed_at = NOW(),
+		    expires_at = EXCLUDED.expires_at
+		WHERE leases.holder = EXCLUDED.holder OR leases.expires_at < NOW()
+		RETURNING name, holder, acquired_at, renewed_at, expires_at
+	`
+
+	var lease types.Lease
+	err := s.db.QueryRow(ctx, query, name, holder, ttl).Scan(
+		&lease.Name,
+		&lease.Holder,
+		&lease.AcquiredAt,
+		&lease.RenewedAt,
+		&lease.ExpiresAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			// Someone else holds a valid lease
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+
+	return &lease, true, nil
+}
+
+// Release gives up the named lease if holder still owns it
+func (s *LeaseStore) Release(ctx context.Context, name, holder string) error {
+	query := `DELETE FROM leases WHERE name = $1 AND holder = $2`
+
+	if _, err := s.db.Exec(ctx, query, name, holder); err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+
+	return nil
+}
+
+// GetLease retrieves the current state of the named lease
+func (s *LeaseStore) GetLease(ctx context.Context, name string) (*types.Lease, error) {
+	query := `
+		SELECT name, holder, acquired_at, renewed_at, expires_at
+		FROM leases
+		WHERE name = $1
+	`
+
+	var lease types.Lease
+	err := s.db.QueryRow(ctx, query, name).Scan(
+		&lease.Name,
+		&lease.Holder,
+		&lease.AcquiredAt,
+		&lease.RenewedAt,
+		&lease.ExpiresAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("lease not found")
+		}
+		return nil, fmt.Errorf("failed to get lease: %w", err)
+	}
+
+	return &lease, nil
+}
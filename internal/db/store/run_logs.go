@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// RunLogStore handles the output chunks of in-flight runs
+type RunLogStore struct {
+	db DBTX
+}
+
+// NewRunLogStore creates a new run log store
+func NewRunLogStore(db DBTX) *RunLogStore {
+	return &RunLogStore{db: db}
+}
+
+// AppendRunLogs stores output chunks of a run in order. Bytes that aren't
+// valid UTF-8, and NUL bytes, are replaced since text columns can't hold them.
+func (s *RunLogStore) AppendRunLogs(ctx context.Context, runID uuid.UUID, logs []types.RunLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	streams := make([]string, len(logs))
+	data := make([]string, len(logs))
+	for i, log := range logs {
+		streams[i] = log.Stream
+		data[i] = strings.ReplaceAll(strings.ToValidUTF8(log.Data, "\uFFFD"), "\x00", "\uFFFD")
+	}
+
+	query := `
+		INSERT INTO run_logs (run_id, stream, data)
+		SELECT $1, l.stream, l.data
+		FROM unnest($2::text[], $3::text[]) WITH ORDINALITY AS l(stream, data, n)
+		ORDER BY l.n
+	`
+
+	if _, err := s.db.Exec(ctx, query, runID, streams, data); err != nil {
+		return fmt.Errorf("failed to append run logs: %w", err)
+	}
+	return nil
+}
+
+// ListRunLogs returns up to limit output chunks of a run with an id after
+// afterID, oldest first
+func (s *RunLogStore) ListRunLogs(ctx context.Context, runID uuid.UUID, afterID int64, limit int) ([]*types.RunLog, error) {
+	query := `
+		SELECT id, run_id, stream, data, created_at
+		FROM run_logs
+		WHERE run_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`
+
+	rows, err := s.db.Query(ctx, query, runID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query run logs: %w", err)
+	}
+
+	logs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*types.RunLog, error) {
+		var log types.RunLog
+		err := row.Scan(&log.ID, &log.RunID, &log.Stream, &log.Data, &log.CreatedAt)
+		return &log, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan run logs: %w", err)
+	}
+
+	return logs, nil
+}
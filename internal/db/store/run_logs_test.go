@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+func TestRunLogStore_AppendAndList(t *testing.T) {
+	jobStore, runStore := setupRunTestDB(t)
+	if jobStore == nil {
+		return // Test was skipped
+	}
+
+	ctx := context.Background()
+	job := createTestJob(t, jobStore, "test_run_logs")
+	logStore := NewRunLogStore(runStore.db)
+
+	run := &types.Run{
+		JobID:       job.ID,
+		Status:      types.RunStatusScheduled,
+		AttemptNum:  1,
+		ScheduledAt: time.Now(),
+	}
+	if err := runStore.CreateRun(ctx, run); err != nil {
+		t.Fatalf("Failed to create run: %v", err)
+	}
+
+	logs := []types.RunLog{
+		{Stream: types.LogStreamStdout, Data: "first\n"},
+		{Stream: types.LogStreamStderr, Data: "bad \xff byte\n"},
+		{Stream: types.LogStreamStdout, Data: "third\n"},
+	}
+	if err := logStore.AppendRunLogs(ctx, run.ID, logs); err != nil {
+		t.Fatalf("Failed to append logs: %v", err)
+	}
+
+	stored, err := logStore.ListRunLogs(ctx, run.ID, 0, 10)
+	if err != nil {
+		t.Fatalf("Failed to list logs: %v", err)
+	}
+	if len(stored) != 3 {
+		t.Fatalf("Expected 3 logs, got %d", len(stored))
+	}
+	if stored[0].Data != "first\n" || stored[1].Data != "bad � byte\n" || stored[2].Data != "third\n" {
+		t.Errorf("Expected logs in order with invalid bytes replaced, got %q %q %q", stored[0].Data, stored[1].Data, stored[2].Data)
+	}
+
+	// Reading on from a log returns only later ones
+	later, err := logStore.ListRunLogs(ctx, run.ID, stored[0].ID, 10)
+	if err != nil {
+		t.Fatalf("Failed to list logs: %v", err)
+	}
+	if len(later) != 2 || later[0].ID != stored[1].ID {
+		t.Errorf("Expected the 2 logs after the first, got %d", len(later))
+	}
+}
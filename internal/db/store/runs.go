@@ -2,42 +2,143 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
 )
 
+// runColumns lists the columns read for every run query, in scanRun order
+const runColumns = `id, job_id, status, attempt_num, retry_of, trigger, backfill_id, triggered_by,
+	overrides, scheduled_at, started_at, finished_at, output, stdout, stderr, exit_code,
+	term_signal, output_truncated, result, error_msg, claimed_by, claimed_at, heartbeat_at,
+	cancel_requested_at, created_at, updated_at`
+
+// claimLockKey is the advisory lock that serializes claims, so concurrency
+// limits that span several runs are checked against a stable view
+const claimLockKey = 0x6173746572 // "aster"
+
 // RunStore handles all run-related database operations
 type RunStore struct {
-	pool *pgxpool.Pool
+	db DBTX
 }
 
 // NewRunStore creates a new run store
-func NewRunStore(pool *pgxpool.Pool) *RunStore {
-	return &RunStore{pool: pool}
+func NewRunStore(db DBTX) *RunStore {
+	return &RunStore{db: db}
+}
+
+// WithTx returns a copy of the store that runs its queries in tx
+func (s *RunStore) WithTx(tx pgx.Tx) *RunStore {
+	return &RunStore{db: tx}
+}
+
+// scanRun reads a single run row selected with runColumns
+func scanRun(row pgx.Row) (*types.Run, error) {
+	var run types.Run
+	var overridesJSON, resultJSON []byte
+	err := row.Scan(
+		&run.ID,
+		&run.JobID,
+		&run.Status,
+		&run.AttemptNum,
+		&run.RetryOf,
+		&run.Trigger,
+		&run.BackfillID,
+		&run.TriggeredBy,
+		&overridesJSON, // NULL unless the run overrides its job
+		&run.ScheduledAt,
+		&run.StartedAt,
+		&run.FinishedAt,
+		&run.Output,
+		&run.Stdout,
+		&run.Stderr,
+		&run.ExitCode,
+		&run.TermSignal,
+		&run.OutputTruncated,
+		&resultJSON, // NULL unless the job reported a result
+		&run.ErrorMsg,
+		&run.ClaimedBy,
+		&run.ClaimedAt,
+		&run.HeartbeatAt,
+		&run.CancelRequestedAt,
+		&run.CreatedAt,
+		&run.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if overridesJSON != nil {
+		if err := json.Unmarshal(overridesJSON, &run.Overrides); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal overrides: %w", err)
+		}
+	}
+	if resultJSON != nil {
+		run.Result = json.RawMessage(resultJSON)
+	}
+
+	return &run, nil
+}
+
+// collectRuns scans every row returned by a run query
+func collectRuns(rows pgx.Rows) ([]*types.Run, error) {
+	defer rows.Close()
+
+	var runs []*types.Run
+
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan run: %w", err)
+		}
+
+		runs = append(runs, run)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", rows.Err())
+	}
+
+	return runs, nil
 }
 
 // CreateRun inserts a new run into the database
 func (s *RunStore) CreateRun(ctx context.Context, run *types.Run) error {
 	query := `
-		INSERT INTO runs (id, job_id, status, attempt_num, scheduled_at, started_at, finished_at, output, error_msg)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO runs (id, job_id, status, attempt_num, retry_of, trigger, backfill_id,
+			triggered_by, overrides, scheduled_at, started_at, finished_at, output, error_msg)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	// Generate UUID if not provided
 	if run.ID == uuid.Nil {
 		run.ID = uuid.New()
 	}
+	if run.Trigger == "" {
+		run.Trigger = types.RunTriggerSchedule
+	}
 
-	_, err := s.pool.Exec(ctx, query,
+	overridesJSON, err := marshalOverrides(run.Overrides)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(ctx, query,
 		run.ID,
 		run.JobID,
 		run.Status,
 		run.AttemptNum,
+		run.RetryOf,
+		run.Trigger,
+		run.BackfillID,
+		run.TriggeredBy,
+		overridesJSON,
 		run.ScheduledAt,
 		run.StartedAt,
 		run.FinishedAt,
@@ -52,30 +153,73 @@ func (s *RunStore) CreateRun(ctx context.Context, run *types.Run) error {
 	return nil
 }
 
-// GetRun retrieves a run by ID
-func (s *RunStore) GetRun(ctx context.Context, id uuid.UUID) (*types.Run, error) {
+// CreateRunOnce inserts a scheduler-triggered run unless one already exists
+// for the same job, scheduled_at and attempt_num. It reports whether the run
+// was created.
+func (s *RunStore) CreateRunOnce(ctx context.Context, run *types.Run) (bool, error) {
 	query := `
-		SELECT id, job_id, status, attempt_num, scheduled_at, started_at, 
-		    finished_at, output, error_msg, created_at, updated_at
-		FROM runs 
-		WHERE id = $1
+		INSERT INTO runs (id, job_id, status, attempt_num, retry_of, trigger, backfill_id,
+			triggered_by, overrides, scheduled_at, started_at, finished_at, output, error_msg)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (job_id, scheduled_at, attempt_num) WHERE trigger = 'schedule' DO NOTHING
 	`
 
-	var run types.Run
-	err := s.pool.QueryRow(ctx, query, id).Scan(
-		&run.ID,
-		&run.JobID,
-		&run.Status,
-		&run.AttemptNum,
-		&run.ScheduledAt,
-		&run.StartedAt,
-		&run.FinishedAt,
-		&run.Output,
-		&run.ErrorMsg,
-		&run.CreatedAt,
-		&run.UpdatedAt,
+	// Generate UUID if not provided
+	if run.ID == uuid.Nil {
+		run.ID = uuid.New()
+	}
+	if run.Trigger == "" {
+		run.Trigger = types.RunTriggerSchedule
+	}
+
+	overridesJSON, err := marshalOverrides(run.Overrides)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := s.db.Exec(ctx, query,
+		run.ID,
+		run.JobID,
+		run.Status,
+		run.AttemptNum,
+		run.RetryOf,
+		run.Trigger,
+		run.BackfillID,
+		run.TriggeredBy,
+		overridesJSON,
+		run.ScheduledAt,
+		run.StartedAt,
+		run.FinishedAt,
+		run.Output,
+		run.ErrorMsg,
 	)
 
+	if err != nil {
+		return false, fmt.Errorf("failed to create run: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// marshalOverrides encodes run overrides for storage, keeping NULL for none
+func marshalOverrides(overrides *types.RunOverrides) ([]byte, error) {
+	if overrides == nil {
+		return nil, nil
+	}
+
+	overridesJSON, err := json.Marshal(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal overrides: %w", err)
+	}
+
+	return overridesJSON, nil
+}
+
+// GetRun retrieves a run by ID
+func (s *RunStore) GetRun(ctx context.Context, id uuid.UUID) (*types.Run, error) {
+	query := `SELECT ` + runColumns + ` FROM runs WHERE id = $1`
+
+	run, err := scanRun(s.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("run not found")
@@ -83,82 +227,93 @@ func (s *RunStore) GetRun(ctx context.Context, id uuid.UUID) (*types.Run, error)
 		return nil, fmt.Errorf("failed to get run: %w", err)
 	}
 
-	return &run, nil
+	return run, nil
 }
 
-// ListRuns returns runs, optionally filtered by job ID
-func (s *RunStore) ListRuns(ctx context.Context, jobID *uuid.UUID, limit, offset int) ([]*types.Run, error) {
-	var query string
+// RunFilter narrows down the runs returned by ListRuns. Nil fields match any run.
+type RunFilter struct {
+	JobID      *uuid.UUID
+	Trigger    *types.RunTrigger
+	BackfillID *uuid.UUID
+	Result     json.RawMessage // Runs whose result contains this JSON document
+}
+
+// ListRuns returns runs matching filter, newest first
+func (s *RunStore) ListRuns(ctx context.Context, filter RunFilter, limit, offset int) ([]*types.Run, error) {
+	var conditions []string
 	var args []any
 
-	if jobID != nil {
-		query = `
-			SELECT id, job_id, status, attempt_num, scheduled_at, started_at,
-			  finished_at, output, error_msg, created_at, updated_at
-			FROM runs
-			WHERE job_id = $1
-			ORDER BY created_at DESC
-			LIMIT $2 OFFSET $3
-		`
-		args = []any{*jobID, limit, offset}
-	} else {
-		query = `
-			SELECT id, job_id, status, attempt_num, scheduled_at, started_at,
-			  finished_at, output, error_msg, created_at, updated_at
-			FROM runs
-			ORDER BY created_at DESC
-			LIMIT $1 OFFSET $2
-		`
-		args = []any{limit, offset}
+	addCondition := func(column string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", column, len(args)))
 	}
 
-	rows, err := s.pool.Query(ctx, query, args...)
+	if filter.JobID != nil {
+		addCondition("job_id", *filter.JobID)
+	}
+	if filter.Trigger != nil {
+		addCondition("trigger", *filter.Trigger)
+	}
+	if filter.BackfillID != nil {
+		addCondition("backfill_id", *filter.BackfillID)
+	}
+	if filter.Result != nil {
+		args = append(args, []byte(filter.Result))
+		conditions = append(conditions, fmt.Sprintf("result @> $%d", len(args)))
+	}
+
+	query := `SELECT ` + runColumns + ` FROM runs`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, limit, offset)
+	query += fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query runs: %w", err)
 	}
-	defer rows.Close()
 
-	var runs []*types.Run
+	return collectRuns(rows)
+}
 
-	for rows.Next() {
-		var run types.Run
-		err := rows.Scan(
-			&run.ID,
-			&run.JobID,
-			&run.Status,
-			&run.AttemptNum,
-			&run.ScheduledAt,
-			&run.StartedAt,
-			&run.FinishedAt,
-			&run.Output,
-			&run.ErrorMsg,
-			&run.CreatedAt,
-			&run.UpdatedAt,
+// GetRunAttempts returns every attempt in the retry chain the given run
+// belongs to, from the original run through its latest retry
+func (s *RunStore) GetRunAttempts(ctx context.Context, id uuid.UUID) ([]*types.Run, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, retry_of FROM runs WHERE id = $1
+			UNION ALL
+			SELECT r.id, r.retry_of FROM runs r JOIN ancestors a ON r.id = a.retry_of
+		), chain AS (
+			SELECT id FROM ancestors WHERE retry_of IS NULL
+			UNION ALL
+			SELECT r.id FROM runs r JOIN chain c ON r.retry_of = c.id
 		)
+		SELECT ` + runColumns + `
+		FROM runs
+		WHERE id IN (SELECT id FROM chain)
+		ORDER BY attempt_num ASC, created_at ASC
+	`
 
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan run: %w", err)
-		}
-
-		runs = append(runs, &run)
-	}
-
-	if rows.Err() != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", rows.Err())
+	rows, err := s.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query run attempts: %w", err)
 	}
 
-	return runs, nil
+	return collectRuns(rows)
 }
 
 // UpdateRunStatus updates a run's status and related fields
 func (s *RunStore) UpdateRunStatus(ctx context.Context, runID uuid.UUID, status types.RunStatus, output string, errorMsg *string) error {
 	query := `
-		UPDATE runs 
+		UPDATE runs
 		SET status = $2, output = $3, error_msg = $4, updated_at = NOW()
 		WHERE id = $1
 	`
 
-	result, err := s.pool.Exec(ctx, query, runID, status, output, errorMsg)
+	result, err := s.db.Exec(ctx, query, runID, status, output, errorMsg)
 	if err != nil {
 		return fmt.Errorf("failed to update run status: %w", err)
 	}
@@ -170,91 +325,320 @@ func (s *RunStore) UpdateRunStatus(ctx context.Context, runID uuid.UUID, status
 	return nil
 }
 
-// MarkRunStarted marks a run as started
-func (s *RunStore) MarkRunStarted(ctx context.Context, runID uuid.UUID) error {
+// ClaimRuns atomically moves up to limit due scheduled runs to the claimed
+// state on behalf of workerID. Rows locked by a concurrent claim are skipped,
+// so two workers never receive the same run. Runs scheduled in the future
+// (such as retries waiting out their backoff) are left alone. Runs are also
+// held back while their job is at its concurrency limit (one active run for
+// forbid and replace, max_concurrent_runs otherwise) or their backfill is at
+// its parallelism.
+func (s *RunStore) ClaimRuns(ctx context.Context, workerID string, limit int) ([]*types.Run, error) {
 	query := `
-		UPDATE runs 
-		SET status = $2, started_at = NOW(), updated_at = NOW()
-		WHERE id = $1
+		WITH active_jobs AS (
+			SELECT job_id, COUNT(*) AS n
+			FROM runs
+			WHERE status IN ($2, $5)
+			GROUP BY job_id
+		), active_backfills AS (
+			SELECT backfill_id, COUNT(*) AS n
+			FROM runs
+			WHERE backfill_id IS NOT NULL AND status IN ($2, $5)
+			GROUP BY backfill_id
+		), due AS (
+			SELECT id, job_id, backfill_id, scheduled_at,
+			       ROW_NUMBER() OVER (PARTITION BY job_id ORDER BY scheduled_at, id) AS job_rn,
+			       ROW_NUMBER() OVER (PARTITION BY backfill_id ORDER BY scheduled_at, id) AS backfill_rn
+			FROM runs
+			WHERE status = $1 AND scheduled_at <= NOW()
+		), job_limits AS (
+			SELECT id AS job_id,
+			       CASE
+			           WHEN concurrency_policy IN ($6, $7) THEN 1
+			           WHEN max_concurrent_runs > 0 THEN max_concurrent_runs
+			       END AS max_active
+			FROM jobs
+		), eligible AS (
+			SELECT due.id
+			FROM due
+			JOIN job_limits jl ON jl.job_id = due.job_id
+			LEFT JOIN active_jobs aj ON aj.job_id = due.job_id
+			LEFT JOIN backfills b ON b.id = due.backfill_id
+			LEFT JOIN active_backfills ab ON ab.backfill_id = due.backfill_id
+			WHERE (jl.max_active IS NULL OR due.job_rn <= jl.max_active - COALESCE(aj.n, 0))
+			  AND (due.backfill_id IS NULL OR due.backfill_rn <= b.parallelism - COALESCE(ab.n, 0))
+			ORDER BY due.scheduled_at ASC
+			LIMIT $4
+		)
+		UPDATE runs
+		SET status = $2, claimed_by = $3, claimed_at = NOW(), heartbeat_at = NOW(), updated_at = NOW()
+		WHERE id IN (
+			SELECT id
+			FROM runs
+			WHERE id IN (SELECT id FROM eligible) AND status = $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + runColumns
+
+	var runs []*types.Run
+	err := RunInTx(ctx, s.db, func(tx pgx.Tx) error {
+		// Concurrency limits count runs other workers are claiming right now
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, claimLockKey); err != nil {
+			return fmt.Errorf("failed to lock run claims: %w", err)
+		}
+
+		rows, err := tx.Query(ctx, query,
+			types.RunStatusScheduled,
+			types.RunStatusClaimed,
+			workerID,
+			limit,
+			types.RunStatusRunning,
+			types.ConcurrencyForbid,
+			types.ConcurrencyReplace,
+		)
+		if err != nil {
+			return err
+		}
+
+		runs, err = collectRuns(rows)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// CountActiveRuns returns how many runs of a job are claimed or running
+func (s *RunStore) CountActiveRuns(ctx context.Context, jobID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM runs WHERE job_id = $1 AND status IN ($2, $3)`
+
+	var count int
+	err := s.db.QueryRow(ctx, query, jobID, types.RunStatusClaimed, types.RunStatusRunning).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count active runs: %w", err)
+	}
+
+	return count, nil
+}
+
+// RequestCancelActiveRuns flags every claimed or running run of a job for
+// cancellation. Workers notice the flag and stop the run. It returns how
+// many runs were flagged.
+func (s *RunStore) RequestCancelActiveRuns(ctx context.Context, jobID uuid.UUID) (int, error) {
+	query := `
+		UPDATE runs
+		SET cancel_requested_at = NOW(), updated_at = NOW()
+		WHERE job_id = $1 AND status IN ($2, $3) AND cancel_requested_at IS NULL
+	`
+
+	result, err := s.db.Exec(ctx, query, jobID, types.RunStatusClaimed, types.RunStatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to request run cancellation: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// CancelRun cancels a single run. A scheduled run is cancelled outright,
+// while a claimed or running run is flagged so the worker that owns it stops
+// it and records the final status. It returns the updated run.
+func (s *RunStore) CancelRun(ctx context.Context, runID uuid.UUID) (*types.Run, error) {
+	query := `
+		UPDATE runs
+		SET status = CASE WHEN status = $2 THEN $3 ELSE status END,
+			finished_at = CASE WHEN status = $2 THEN NOW() ELSE finished_at END,
+			error_msg = CASE WHEN status = $2 THEN $6 ELSE error_msg END,
+			cancel_requested_at = COALESCE(cancel_requested_at, NOW()),
+			updated_at = NOW()
+		WHERE id = $1 AND status IN ($2, $4, $5)
+		RETURNING ` + runColumns
+
+	run, err := scanRun(s.db.QueryRow(ctx, query, runID,
+		types.RunStatusScheduled,
+		types.RunStatusCancelled,
+		types.RunStatusClaimed,
+		types.RunStatusRunning,
+		"cancelled before it started",
+	))
+	if err == nil {
+		return run, nil
+	}
+	if err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("failed to cancel run: %w", err)
+	}
+
+	// Nothing was updated, so the run is either missing or already over
+	if _, err := s.GetRun(ctx, runID); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("run already finished")
+}
+
+// GetCancelRequested returns which of the given runs have been flagged for cancellation
+func (s *RunStore) GetCancelRequested(ctx context.Context, runIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(runIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT id FROM runs WHERE id = ANY($1) AND cancel_requested_at IS NOT NULL`
+
+	rows, err := s.db.Query(ctx, query, runIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cancel requests: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan cancel requests: %w", err)
+	}
+
+	return ids, nil
+}
+
+// MarkRunStarted marks a run as started. It reports false without changing
+// the run if it was cancelled or flagged for cancellation before it started.
+func (s *RunStore) MarkRunStarted(ctx context.Context, runID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE runs
+		SET status = $2, started_at = NOW(), heartbeat_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status IN ($3, $4) AND cancel_requested_at IS NULL
+	`
+
+	result, err := s.db.Exec(ctx, query, runID, types.RunStatusRunning, types.RunStatusScheduled, types.RunStatusClaimed)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark run as started: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// ReleaseRun hands a claimed run that never started back to the queue so
+// another worker can pick it up
+func (s *RunStore) ReleaseRun(ctx context.Context, runID uuid.UUID) error {
+	query := `
+		UPDATE runs
+		SET status = $2, claimed_by = NULL, claimed_at = NULL, heartbeat_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = $3
 	`
 
-	result, err := s.pool.Exec(ctx, query, runID, types.RunStatusRunning)
+	result, err := s.db.Exec(ctx, query, runID, types.RunStatusScheduled, types.RunStatusClaimed)
 	if err != nil {
-		return fmt.Errorf("failed to mark run as started: %w", err)
+		return fmt.Errorf("failed to release run: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("run not found")
+		return fmt.Errorf("run not found or not claimed")
 	}
 
 	return nil
 }
 
-// MarkRunFinished marks a run as finished with final status
-func (s *RunStore) MarkRunFinished(ctx context.Context, runID uuid.UUID, status types.RunStatus, output string, errorMsg *string) error {
+// MarkRunFinished marks a run as finished with final status. Runs that
+// already finished, for example because the reaper gave up on their worker,
+// are left alone.
+func (s *RunStore) MarkRunFinished(ctx context.Context, runID uuid.UUID, status types.RunStatus, output types.RunOutput, errorMsg *string) error {
 	query := `
-		UPDATE runs 
-		SET status = $2, finished_at = NOW(), output = $3, error_msg = $4, updated_at = NOW()
-		WHERE id = $1
+		UPDATE runs
+		SET status = $2, finished_at = NOW(), output = $3, stdout = $4, stderr = $5, exit_code = $6,
+			term_signal = $7, output_truncated = $8, result = $9, error_msg = $10, updated_at = NOW()
+		WHERE id = $1 AND status IN ($11, $12)
 	`
 
-	result, err := s.pool.Exec(ctx, query, runID, status, output, errorMsg)
+	result, err := s.db.Exec(ctx, query, runID, status,
+		output.Output,
+		output.Stdout,
+		output.Stderr,
+		output.ExitCode,
+		output.Signal,
+		output.Truncated,
+		[]byte(output.Result),
+		errorMsg,
+		types.RunStatusClaimed,
+		types.RunStatusRunning,
+	)
 	if err != nil {
 		return fmt.Errorf("failed to mark run as finished: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("run not found")
+		return fmt.Errorf("run not found or already finished")
 	}
 
 	return nil
 }
 
-// GetRunsByStatus returns runs with a specific status
-func (s *RunStore) GetRunsByStatus(ctx context.Context, status types.RunStatus, limit int) ([]*types.Run, error) {
+// HeartbeatRuns records that workerID is still executing the given runs. It
+// returns the runs the worker still owns; any others have been reaped or
+// finished elsewhere and should be abandoned.
+func (s *RunStore) HeartbeatRuns(ctx context.Context, workerID string, runIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(runIDs) == 0 {
+		return nil, nil
+	}
+
 	query := `
-		SELECT id, job_id, status, attempt_num, scheduled_at, started_at,
-		  finished_at, output, error_msg, created_at, updated_at
-		FROM runs
-		WHERE status = $1
-		ORDER BY scheduled_at ASC
-		LIMIT $2
+		UPDATE runs
+		SET heartbeat_at = NOW()
+		WHERE id = ANY($1) AND claimed_by = $2 AND status IN ($3, $4)
+		RETURNING id
 	`
 
-	rows, err := s.pool.Query(ctx, query, status, limit)
+	rows, err := s.db.Query(ctx, query, runIDs, workerID, types.RunStatusClaimed, types.RunStatusRunning)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query runs by status: %w", err)
+		return nil, fmt.Errorf("failed to record heartbeats: %w", err)
 	}
-	defer rows.Close()
 
-	var runs []*types.Run
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan heartbeats: %w", err)
+	}
 
-	for rows.Next() {
-		var run types.Run
-		err := rows.Scan(
-			&run.ID,
-			&run.JobID,
-			&run.Status,
-			&run.AttemptNum,
-			&run.ScheduledAt,
-			&run.StartedAt,
-			&run.FinishedAt,
-			&run.Output,
-			&run.ErrorMsg,
-			&run.CreatedAt,
-			&run.UpdatedAt,
+	return ids, nil
+}
+
+// FailOrphanedRuns marks claimed or running runs whose last heartbeat is
+// older than before as failed, since the worker that owned them is gone.
+// It returns the failed runs so they can be retried.
+func (s *RunStore) FailOrphanedRuns(ctx context.Context, before time.Time, errorMsg string) ([]*types.Run, error) {
+	query := `
+		UPDATE runs
+		SET status = $1, finished_at = NOW(), error_msg = $2, updated_at = NOW()
+		WHERE id IN (
+			SELECT id
+			FROM runs
+			WHERE status IN ($3, $4) AND heartbeat_at < $5
+			FOR UPDATE SKIP LOCKED
 		)
+		RETURNING ` + runColumns
+
+	rows, err := s.db.Query(ctx, query,
+		types.RunStatusFailed,
+		errorMsg,
+		types.RunStatusClaimed,
+		types.RunStatusRunning,
+		before,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail orphaned runs: %w", err)
+	}
 
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan run: %w", err)
-		}
+	return collectRuns(rows)
+}
 
-		runs = append(runs, &run)
-	}
+// GetRunsByStatus returns runs with a specific status
+func (s *RunStore) GetRunsByStatus(ctx context.Context, status types.RunStatus, limit int) ([]*types.Run, error) {
+	query := `
+		SELECT ` + runColumns + `
+		FROM runs
+		WHERE status = $1
+		ORDER BY scheduled_at ASC
+		LIMIT $2
+	`
 
-	if rows.Err() != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", rows.Err())
+	rows, err := s.db.Query(ctx, query, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runs by status: %w", err)
 	}
 
-	return runs, nil
+	return collectRuns(rows)
 }
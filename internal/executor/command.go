@@ -0,0 +1,397 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// TypeCommand runs a local binary with the job's args and env. It is the
+// default for jobs that don't set a type.
+const TypeCommand = "command"
+
+// shellPath is the shell that runs commands of jobs in shell mode
+const shellPath = "/bin/sh"
+
+func init() {
+	Register(TypeCommand, func(logger *zap.Logger, settings Settings) Executor {
+		return NewCommandExecutor(logger, settings)
+	})
+}
+
+// CommandConfig is the config of a command job
+type CommandConfig struct {
+	Shell          bool   `json:"shell,omitempty"`            // Run the command line with /bin/sh -c
+	Template       bool   `json:"template,omitempty"`         // Render the command, args and env values as templates
+	WorkingDir     string `json:"working_dir,omitempty"`      // Absolute directory to run in
+	Stdin          string `json:"stdin,omitempty"`            // Written to the command's standard input
+	EnvMode        string `json:"env_mode,omitempty"`         // How the worker's environment is used, default merge
+	MaxOutputBytes int    `json:"max_output_bytes,omitempty"` // Output kept per stream, default 1 MiB
+
+	// KillGrace is how long the command may take to exit after SIGTERM
+	// before it is killed, default 10s
+	KillGrace types.Duration `json:"kill_grace,omitempty"`
+
+	// Limits caps the resources the command may use
+	Limits ResourceLimits `json:"limits"`
+
+	// The account to run as, by name or numeric ID, when the worker allows
+	// it. The group defaults to the user's primary group.
+	RunAsUser  string `json:"run_as_user,omitempty"`
+	RunAsGroup string `json:"run_as_group,omitempty"`
+}
+
+// parseCommandConfig reads and checks a command job's config
+func parseCommandConfig(job *types.Job) (*CommandConfig, error) {
+	var cfg CommandConfig
+	if err := decodeConfig(job, &cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.WorkingDir != "" && !filepath.IsAbs(cfg.WorkingDir) {
+		return nil, fmt.Errorf("config.working_dir must be an absolute path")
+	}
+
+	// A group alone would leave the command with root's uid
+	if cfg.RunAsGroup != "" && cfg.RunAsUser == "" {
+		return nil, fmt.Errorf("config.run_as_group requires config.run_as_user")
+	}
+
+	if cfg.EnvMode == "" {
+		cfg.EnvMode = EnvModeMerge
+	}
+	if err := validateEnvMode(cfg.EnvMode); err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxOutputBytes < 0 || cfg.MaxOutputBytes > maxOutputBytesLimit {
+		return nil, fmt.Errorf("config.max_output_bytes must be between 0 and %d", maxOutputBytesLimit)
+	}
+	if cfg.MaxOutputBytes == 0 {
+		cfg.MaxOutputBytes = defaultMaxOutputBytes
+	}
+
+	if cfg.KillGrace < 0 || time.Duration(cfg.KillGrace) > maxKillGrace {
+		return nil, fmt.Errorf("config.kill_grace must be between 0 and %s", maxKillGrace)
+	}
+	if cfg.KillGrace == 0 {
+		cfg.KillGrace = types.Duration(defaultKillGrace)
+	}
+
+	if err := cfg.Limits.validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// CommandExecutor handles running jobs locally
+type CommandExecutor struct {
+	settings Settings
+	logger   *zap.Logger
+}
+
+// NewCommandExecutor creates a new command executor
+func NewCommandExecutor(logger *zap.Logger, settings Settings) *CommandExecutor {
+	return &CommandExecutor{
+		settings: settings,
+		logger:   logger,
+	}
+}
+
+// Validate checks that a command job's config is well formed and that its
+// binary and working directory exist. Workers check them again before each
+// run, as they may not see the same files as the API. Templated commands are
+// only checked once rendered on the worker.
+func (e *CommandExecutor) Validate(job *types.Job) error {
+	if job.Command == "" {
+		return fmt.Errorf("command is required")
+	}
+
+	cfg, err := parseCommandConfig(job)
+	if err != nil {
+		return err
+	}
+	if err := validateEnv(cfg.EnvMode, job.Env); err != nil {
+		return err
+	}
+	if cfg.Template {
+		if err := validateCommandTemplates(job); err != nil {
+			return err
+		}
+	} else if err := e.checkCommand(job.Command, cfg); err != nil {
+		return err
+	}
+	return e.settings.runAsAllowed(cfg)
+}
+
+// Execute runs a job and returns the result
+func (e *CommandExecutor) Execute(ctx context.Context, job *types.Job, run *types.Run) *ExecutionResult {
+	result := &ExecutionResult{
+		StartTime: time.Now(),
+		Status:    types.RunStatusRunning,
+	}
+
+	cfg, err := parseCommandConfig(job)
+	if err != nil {
+		return e.failBeforeStart(result, err)
+	}
+
+	// Jobs that opt in have their command, args and env values rendered as
+	// templates over the run
+	if cfg.Template {
+		job, err = renderCommandTemplates(job, run)
+		if err != nil {
+			return e.failBeforeStart(result, err)
+		}
+	}
+
+	// In shell mode the command is a shell command line, and args are
+	// available to it as $1, $2 and so on
+	name, args := job.Command, job.Args
+	if cfg.Shell {
+		name = shellPath
+		args = append([]string{"-c", job.Command, "sh"}, job.Args...)
+	}
+
+	if err := e.checkCommand(job.Command, cfg); err != nil {
+		return e.failBeforeStart(result, err)
+	}
+
+	// The worker's allowlists are checked again in case they changed since
+	// the job was saved
+	var account *runAsAccount
+	if cfg.RunAsUser != "" || cfg.RunAsGroup != "" {
+		if err := e.settings.runAsAllowed(cfg); err != nil {
+			return e.failBeforeStart(result, err)
+		}
+		account, err = resolveRunAs(cfg.RunAsUser, cfg.RunAsGroup)
+		if err != nil {
+			return e.failBeforeStart(result, err)
+		}
+	}
+
+	e.logger.Info("Starting job execution",
+		zap.String("job_id", job.ID.String()),
+		zap.String("job_name", job.Name),
+		zap.String("command", job.Command),
+		zap.Strings("args", job.Args),
+		zap.Bool("shell", cfg.Shell),
+		zap.String("run_as_user", cfg.RunAsUser))
+
+	// Create command context with timeout if specified
+	cmdCtx := ctx
+	if job.Timeout != nil {
+		var cancel context.CancelFunc
+		cmdCtx, cancel = context.WithTimeout(ctx, time.Duration(*job.Timeout))
+		defer cancel()
+	}
+
+	// Create the command in its own process group. Cancellation asks the
+	// whole group to stop with SIGTERM and kills it after the kill grace.
+	cmd := exec.CommandContext(cmdCtx, name, args...)
+	stopper := newGroupStopper(cmd, time.Duration(cfg.KillGrace))
+	cmd.Dir = cfg.WorkingDir
+	if cfg.Stdin != "" {
+		cmd.Stdin = strings.NewReader(cfg.Stdin)
+	}
+
+	if account != nil {
+		if err := setCredential(cmd, account); err != nil {
+			return e.failBeforeStart(result, err)
+		}
+	}
+
+	// The command can report a result by writing JSON to ASTER_RESULT_PATH
+	resultDir, err := newResultDir(account)
+	if err != nil {
+		return e.failBeforeStart(result, err)
+	}
+	defer os.RemoveAll(resultDir)
+
+	cmd.Env = append(e.settings.buildEnv(cfg.EnvMode, job, run, account),
+		asterEnvPrefix+"RESULT_PATH="+resultPath(resultDir))
+
+	// Capture each stream, and both interleaved as the run's output, keeping
+	// only the start and end of long output
+	stdout := newHeadTailBuffer(cfg.MaxOutputBytes)
+	stderr := newHeadTailBuffer(cfg.MaxOutputBytes)
+	combined := newHeadTailBuffer(cfg.MaxOutputBytes)
+	stdoutWriters := []io.Writer{stdout, combined}
+	stderrWriters := []io.Writer{stderr, combined}
+	if logs := logWriterFrom(ctx); logs != nil {
+		stdoutWriters = append(stdoutWriters, streamWriter{logs, types.LogStreamStdout})
+		stderrWriters = append(stderrWriters, streamWriter{logs, types.LogStreamStderr})
+	}
+	cmd.Stdout = io.MultiWriter(stdoutWriters...)
+	cmd.Stderr = io.MultiWriter(stderrWriters...)
+
+	// Give the command a cgroup of its own when the worker has one to
+	// delegate, so its memory and process limits cover everything it spawns
+	var cgroup *runCgroup
+	if e.settings.CgroupPath != "" && cfg.Limits.set() {
+		var cgErr error
+		cgroup, cgErr = newRunCgroup(e.settings.CgroupPath, run.ID, cfg.Limits)
+		if cgErr != nil {
+			e.logger.Warn("Running job without a cgroup",
+				zap.String("job_id", job.ID.String()),
+				zap.String("run_id", run.ID.String()),
+				zap.Error(cgErr))
+		} else {
+			defer cgroup.remove()
+			cgroup.attach(cmd)
+		}
+	}
+
+	// RLIMIT_NPROC counts every process of a user, the worker's own
+	// included, so the process limit is only an rlimit for commands that
+	// run as a user of their own
+	rlimits := cfg.Limits
+	if account == nil || account.user == nil {
+		rlimits.Processes = 0
+		if cfg.Limits.Processes > 0 && cgroup == nil {
+			e.logger.Warn("Process limit needs a cgroup or run_as_user, running job without it",
+				zap.String("job_id", job.ID.String()),
+				zap.String("run_id", run.ID.String()))
+		}
+	}
+
+	// Rlimits are set by the command's own process before it execs
+	var started func() error
+	if rlimits.set() {
+		started, err = wrapRlimits(cmd, rlimits)
+		if err != nil {
+			return e.failBeforeStart(result, err)
+		}
+	}
+
+	err = cmd.Start()
+	if started != nil {
+		if startErr := started(); startErr != nil && err == nil {
+			cmd.Wait()
+			stopper.finish()
+			return e.failBeforeStart(result, startErr)
+		}
+	}
+	if err == nil {
+		err = cmd.Wait()
+	}
+	result.Signal = stopper.finish()
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	result.Output = combined.String()
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	result.OutputTruncated = combined.Truncated() || stdout.Truncated() || stderr.Truncated()
+
+	// Processes killed by a signal have no exit code
+	if cmd.ProcessState != nil {
+		if code := cmd.ProcessState.ExitCode(); code >= 0 {
+			result.ExitCode = &code
+		}
+	}
+
+	// Determine the result status
+	if err != nil {
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			result.Status = types.RunStatusTimedOut
+			result.Error = fmt.Errorf("job timed out after %s", job.Timeout)
+		} else if cmdCtx.Err() == context.Canceled {
+			result.Status = types.RunStatusCancelled
+			result.Error = fmt.Errorf("job was cancelled")
+		} else if limitErr := cfg.Limits.exceeded(cmd.ProcessState, cgroup); limitErr != nil {
+			result.Status = types.RunStatusLimitExceeded
+			result.Error = limitErr
+		} else {
+			result.Status = types.RunStatusFailed
+			result.Error = fmt.Errorf("command failed: %w", err)
+		}
+	} else {
+		result.Status = types.RunStatusSucceeded
+	}
+
+	// Failed runs keep their result too. A malformed one fails a run that
+	// would otherwise succeed, so mistakes in reporting don't go unnoticed.
+	if reported, resultErr := readResult(resultPath(resultDir), result.Stdout); resultErr != nil {
+		if result.Status == types.RunStatusSucceeded {
+			result.Status = types.RunStatusFailed
+			result.Error = resultErr
+		} else {
+			e.logger.Warn("Ignoring invalid run result",
+				zap.String("run_id", run.ID.String()),
+				zap.Error(resultErr))
+		}
+	} else {
+		result.Result = reported
+	}
+
+	e.logger.Info("Job execution completed",
+		zap.String("job_id", job.ID.String()),
+		zap.String("job_name", job.Name),
+		zap.String("status", string(result.Status)),
+		zap.Duration("duration", result.Duration),
+		zap.String("output_preview", e.truncateOutput(result.Output, 200)))
+
+	return result
+}
+
+// failBeforeStart finishes a run whose command could not be started
+func (e *CommandExecutor) failBeforeStart(result *ExecutionResult, err error) *ExecutionResult {
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	result.Status = types.RunStatusFailed
+	result.Error = err
+	return result
+}
+
+// truncateOutput limits output length for logging
+func (e *CommandExecutor) truncateOutput(output string, maxLen int) string {
+	// Remove leading/trailing whitespace and newlines
+	output = strings.TrimSpace(output)
+
+	if len(output) <= maxLen {
+		return output
+	}
+
+	return output[:maxLen] + "... (truncated)"
+}
+
+// checkCommand checks that the working directory and the binary a command
+// job runs exist. In shell mode that binary is the shell.
+func (e *CommandExecutor) checkCommand(command string, cfg *CommandConfig) error {
+	if cfg.WorkingDir != "" {
+		if info, err := os.Stat(cfg.WorkingDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("working directory '%s' does not exist", cfg.WorkingDir)
+		}
+	}
+
+	name := command
+	if cfg.Shell {
+		name = shellPath
+	}
+
+	// Relative paths such as ./run.sh are resolved from the working directory
+	if cfg.WorkingDir != "" && strings.ContainsRune(name, filepath.Separator) && !filepath.IsAbs(name) {
+		name = filepath.Join(cfg.WorkingDir, name)
+	}
+	return e.ValidateCommand(name)
+}
+
+// ValidateCommand checks if a command is likely to work before executing it
+func (e *CommandExecutor) ValidateCommand(command string) error {
+	// Check if command exists in PATH
+	_, err := exec.LookPath(command)
+	if err != nil {
+		return fmt.Errorf("command '%s' not found in PATH: %w", command, err)
+	}
+	return nil
+}
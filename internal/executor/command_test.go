@@ -0,0 +1,666 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+	"github.com/google/uuid"
+)
+
+// newTestRun returns a run of job for executors to execute
+func newTestRun(job *types.Job) *types.Run {
+	return &types.Run{
+		ID:          uuid.New(),
+		JobID:       job.ID,
+		Status:      types.RunStatusRunning,
+		AttemptNum:  1,
+		ScheduledAt: time.Now(),
+	}
+}
+
+func TestExecutor_Execute_Success(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_echo",
+		Command: "echo",
+		Args:    []string{"hello", "world"},
+		Env:     map[string]string{"TEST": "value"},
+	}
+
+	ctx := context.Background()
+	result := executor.Execute(ctx, job, newTestRun(job))
+
+	// Check basic result properties
+	if result.Status != types.RunStatusSucceeded {
+		t.Errorf("Expected status %s, got %s", types.RunStatusSucceeded, result.Status)
+	}
+
+	if result.Error != nil {
+		t.Errorf("Expected no error, got: %v", result.Error)
+	}
+
+	// Check output contains expected text
+	expectedOutput := "hello world"
+	if !containsIgnoreWhitespace(result.Output, expectedOutput) {
+		t.Errorf("Expected output to contain '%s', got: '%s'", expectedOutput, result.Output)
+	}
+
+	// Check timing
+	if result.Duration <= 0 {
+		t.Errorf("Expected positive duration, got: %s", result.Duration)
+	}
+
+	if result.StartTime.IsZero() || result.EndTime.IsZero() {
+		t.Error("Expected start and end times to be set")
+	}
+}
+
+func TestExecutor_Execute_CommandNotFound(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_nonexistent",
+		Command: "nonexistent_command_xyz",
+		Args:    []string{},
+	}
+
+	ctx := context.Background()
+	result := executor.Execute(ctx, job, newTestRun(job))
+
+	// Should fail
+	if result.Status != types.RunStatusFailed {
+		t.Errorf("Expected status %s, got %s", types.RunStatusFailed, result.Status)
+	}
+
+	if result.Error == nil {
+		t.Error("Expected error for nonexistent command")
+	}
+}
+
+func TestExecutor_Execute_Timeout(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	// Job that sleeps for 2 seconds but times out after 100ms
+	timeout := types.Duration(100 * time.Millisecond)
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_timeout",
+		Command: "sleep",
+		Args:    []string{"2"}, // Sleep for 2 seconds
+		Timeout: &timeout,
+	}
+
+	ctx := context.Background()
+	start := time.Now()
+	result := executor.Execute(ctx, job, newTestRun(job))
+	elapsed := time.Since(start)
+
+	// Should timeout
+	if result.Status != types.RunStatusTimedOut {
+		t.Errorf("Expected status %s, got %s", types.RunStatusTimedOut, result.Status)
+	}
+
+	if result.Error == nil {
+		t.Error("Expected timeout error")
+	}
+
+	// Should complete quickly (near the timeout, not the full 2 seconds)
+	if elapsed > time.Duration(timeout)*2 {
+		t.Errorf("Expected execution to complete near timeout (%s), took %s", timeout, elapsed)
+	}
+}
+
+func TestExecutor_Execute_CancelSendsSIGTERM(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	// The script gets a chance to clean up when it receives SIGTERM
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_sigterm",
+		Command: "sh",
+		Args:    []string{"-c", "trap 'echo stopping; exit 0' TERM; echo started; sleep 5 >/dev/null 2>&1 & wait"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	result := executor.Execute(ctx, job, newTestRun(job))
+	elapsed := time.Since(start)
+
+	if result.Status != types.RunStatusCancelled {
+		t.Errorf("Expected status %s, got %s", types.RunStatusCancelled, result.Status)
+	}
+
+	if !strings.Contains(result.Output, "started") || !strings.Contains(result.Output, "stopping") {
+		t.Errorf("Expected output from before and after SIGTERM, got: %q", result.Output)
+	}
+
+	if result.Signal != "SIGTERM" {
+		t.Errorf("Expected the run to be ended by SIGTERM, got %q", result.Signal)
+	}
+
+	// Should stop on SIGTERM rather than waiting to be killed
+	if elapsed > 2*time.Second {
+		t.Errorf("Expected the command to stop promptly, took %s", elapsed)
+	}
+}
+
+func TestExecutor_Execute_KillsProcessGroup(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	// Both the script and the sleep it spawns ignore SIGTERM, and the sleep
+	// holds the output pipe open until it is killed
+	timeout := types.Duration(100 * time.Millisecond)
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_kill_group",
+		Command: "trap '' TERM; sleep 30 & wait",
+		Timeout: &timeout,
+		Config:  json.RawMessage(`{"shell":true,"kill_grace":"200ms"}`),
+	}
+
+	start := time.Now()
+	result := executor.Execute(context.Background(), job, newTestRun(job))
+	elapsed := time.Since(start)
+
+	if result.Status != types.RunStatusTimedOut {
+		t.Errorf("Expected status %s, got %s", types.RunStatusTimedOut, result.Status)
+	}
+
+	if result.Signal != "SIGKILL" {
+		t.Errorf("Expected the run to be ended by SIGKILL, got %q", result.Signal)
+	}
+
+	// The grandchild must die with the group for the run to end this soon
+	if elapsed > 2*time.Second {
+		t.Errorf("Expected the process group to be killed after the grace period, took %s", elapsed)
+	}
+}
+
+func TestExecutor_Execute_WithEnvironment(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_env",
+		Command: "sh",
+		Args:    []string{"-c", "echo $TEST_VAR"},
+		Env:     map[string]string{"TEST_VAR": "test_value"},
+	}
+
+	ctx := context.Background()
+	result := executor.Execute(ctx, job, newTestRun(job))
+
+	if result.Status != types.RunStatusSucceeded {
+		t.Errorf("Expected status %s, got %s", types.RunStatusSucceeded, result.Status)
+	}
+
+	if !containsIgnoreWhitespace(result.Output, "test_value") {
+		t.Errorf("Expected output to contain 'test_value', got: '%s'", result.Output)
+	}
+}
+
+func TestExecutor_Execute_Cancellation(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_cancellation",
+		Command: "sleep",
+		Args:    []string{"10"}, // Sleep for 10 seconds
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Cancel after 100ms
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	result := executor.Execute(ctx, job, newTestRun(job))
+	elapsed := time.Since(start)
+
+	// Should be cancelled
+	if result.Status != types.RunStatusCancelled {
+		t.Errorf("Expected status %s, got %s", types.RunStatusCancelled, result.Status)
+	}
+
+	if result.Error == nil {
+		t.Error("Expected cancellation error")
+	}
+
+	// Should complete quickly
+	if elapsed > time.Second {
+		t.Errorf("Expected execution to complete quickly, took %s", elapsed)
+	}
+}
+
+func TestExecutor_Execute_CommandFailure(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_failure",
+		Command: "sh",
+		Args:    []string{"-c", "exit 1"}, // Command that exits with error code 1
+	}
+
+	ctx := context.Background()
+	result := executor.Execute(ctx, job, newTestRun(job))
+
+	// Should fail
+	if result.Status != types.RunStatusFailed {
+		t.Errorf("Expected status %s, got %s", types.RunStatusFailed, result.Status)
+	}
+
+	if result.Error == nil {
+		t.Error("Expected error for command that exits with non-zero code")
+	}
+
+	// Should still have timing information
+	if result.Duration <= 0 {
+		t.Errorf("Expected positive duration even for failed command, got: %s", result.Duration)
+	}
+}
+
+func TestExecutor_Execute_Shell(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	// Pipes and && work, and args are passed as positional parameters
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_shell",
+		Command: `echo "$1 $2" | tr a-z A-Z && echo done`,
+		Args:    []string{"hello", "shell"},
+		Config:  json.RawMessage(`{"shell":true}`),
+	}
+
+	result := executor.Execute(context.Background(), job, newTestRun(job))
+
+	if result.Status != types.RunStatusSucceeded {
+		t.Fatalf("Expected status %s, got %s: %v", types.RunStatusSucceeded, result.Status, result.Error)
+	}
+
+	if result.Output != "HELLO SHELL\ndone\n" {
+		t.Errorf("Expected shell output, got: %q", result.Output)
+	}
+}
+
+func TestExecutor_Execute_Template(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_template",
+		Command: "echo",
+		Args:    []string{"{{.Job.Name}}", "{{.Attempt}}"},
+	}
+
+	// Args are passed as written unless the job opts in to templates
+	result := executor.Execute(context.Background(), job, newTestRun(job))
+	if result.Output != "{{.Job.Name}} {{.Attempt}}\n" {
+		t.Errorf("Expected args passed as written, got: %q", result.Output)
+	}
+
+	job.Config = json.RawMessage(`{"template":true}`)
+	result = executor.Execute(context.Background(), job, newTestRun(job))
+	if result.Output != "test_template 1\n" {
+		t.Errorf("Expected rendered args, got: %q", result.Output)
+	}
+
+	// Only jobs that opt in have their templates checked
+	job.Args = []string{"{{.Attempt"}
+	if err := executor.Validate(job); err == nil {
+		t.Error("Expected a broken template to be rejected")
+	}
+	job.Config = nil
+	if err := executor.Validate(job); err != nil {
+		t.Errorf("Expected a job without templates to accept {{, got: %v", err)
+	}
+}
+
+func TestExecutor_Execute_WorkingDirAndStdin(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\npwd\ncat\n"), 0o755); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	// Relative commands resolve from the working directory
+	config, _ := json.Marshal(CommandConfig{WorkingDir: dir, Stdin: "from stdin"})
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_working_dir",
+		Command: "./run.sh",
+		Config:  config,
+	}
+
+	result := executor.Execute(context.Background(), job, newTestRun(job))
+
+	if result.Status != types.RunStatusSucceeded {
+		t.Fatalf("Expected status %s, got %s: %v", types.RunStatusSucceeded, result.Status, result.Error)
+	}
+
+	if result.Output != dir+"\nfrom stdin" {
+		t.Errorf("Expected working directory and stdin in output, got: %q", result.Output)
+	}
+
+	// A missing working directory fails without running anything
+	job.Config = json.RawMessage(`{"working_dir":"/nonexistent/aster"}`)
+	result = executor.Execute(context.Background(), job, newTestRun(job))
+
+	if result.Status != types.RunStatusFailed || !strings.Contains(result.Error.Error(), "working directory") {
+		t.Errorf("Expected a missing working directory to fail, got %s %v", result.Status, result.Error)
+	}
+}
+
+func TestExecutor_Execute_SeparateStreams(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_streams",
+		Command: "echo out; echo err >&2; exit 3",
+		Config:  json.RawMessage(`{"shell":true}`),
+	}
+
+	result := executor.Execute(context.Background(), job, newTestRun(job))
+
+	if result.Status != types.RunStatusFailed {
+		t.Errorf("Expected status %s, got %s", types.RunStatusFailed, result.Status)
+	}
+
+	if result.ExitCode == nil || *result.ExitCode != 3 {
+		t.Errorf("Expected exit code 3, got %v", result.ExitCode)
+	}
+
+	if result.Stdout != "out\n" || result.Stderr != "err\n" {
+		t.Errorf("Expected separate streams, got stdout %q and stderr %q", result.Stdout, result.Stderr)
+	}
+
+	if !strings.Contains(result.Output, "out") || !strings.Contains(result.Output, "err") {
+		t.Errorf("Expected both streams in the output, got %q", result.Output)
+	}
+}
+
+func TestExecutor_Execute_OutputLimit(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_output_limit",
+		Command: "seq 1 100000",
+		Config:  json.RawMessage(`{"shell":true,"max_output_bytes":64}`),
+	}
+
+	result := executor.Execute(context.Background(), job, newTestRun(job))
+
+	if result.Status != types.RunStatusSucceeded {
+		t.Fatalf("Expected status %s, got %s: %v", types.RunStatusSucceeded, result.Status, result.Error)
+	}
+
+	if !result.OutputTruncated {
+		t.Error("Expected output to be marked truncated")
+	}
+
+	if !strings.HasPrefix(result.Stdout, "1\n2\n3\n") || !strings.HasSuffix(result.Stdout, "99999\n100000\n") {
+		t.Errorf("Expected the start and end of the output, got %q", result.Stdout)
+	}
+
+	if len(result.Stdout) > 200 {
+		t.Errorf("Expected output near the 64 byte limit, got %d bytes", len(result.Stdout))
+	}
+}
+
+func TestExecutor_Execute_Result(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	tests := []struct {
+		name       string
+		command    string
+		wantStatus types.RunStatus
+		wantResult string
+	}{
+		{"result file", `echo '{"rows": 1234}' > "$ASTER_RESULT_PATH"`, types.RunStatusSucceeded, `{"rows": 1234}`},
+		{"result line", `echo '::aster-result::{"rows": 7}'`, types.RunStatusSucceeded, `{"rows": 7}`},
+		{"failed run keeps result", `echo '{"rows": 1}' > "$ASTER_RESULT_PATH"; exit 3`, types.RunStatusFailed, `{"rows": 1}`},
+		{"invalid result", `echo 'rows=1' > "$ASTER_RESULT_PATH"`, types.RunStatusFailed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &types.Job{
+				ID:      uuid.New(),
+				Name:    "test_result",
+				Command: tt.command,
+				Config:  json.RawMessage(`{"shell":true}`),
+			}
+
+			result := executor.Execute(context.Background(), job, newTestRun(job))
+
+			if result.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s: %v", tt.wantStatus, result.Status, result.Error)
+			}
+			if string(result.Result) != tt.wantResult {
+				t.Errorf("Expected result %s, got %s", tt.wantResult, result.Result)
+			}
+		})
+	}
+}
+
+func TestExecutor_Validate(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	valid := []*types.Job{
+		{Command: "echo", Config: json.RawMessage(`{"shell":false,"working_dir":"` + dir + `","stdin":"data"}`)},
+		{Command: "./run.sh", Config: json.RawMessage(`{"working_dir":"` + dir + `"}`)},
+		{Command: "nonexistent_command_xyz || true", Config: json.RawMessage(`{"shell":true}`)},
+		{Command: "{{.Job.Name}}", Config: json.RawMessage(`{"template":true}`)}, // Checked once rendered
+	}
+	for _, job := range valid {
+		if err := executor.Validate(job); err != nil {
+			t.Errorf("Expected job with command %q and config %s to be valid, got: %v", job.Command, job.Config, err)
+		}
+	}
+
+	invalid := []*types.Job{
+		{Command: ""},
+		{Command: "nonexistent_command_xyz"},
+		{Command: "./missing.sh", Config: json.RawMessage(`{"working_dir":"` + dir + `"}`)},
+		{Command: "echo", Config: json.RawMessage(`{"working_dir":"` + filepath.Join(dir, "missing") + `"}`)},
+		{Command: "echo", Config: json.RawMessage(`{"working_dir":"srv/app"}`)},
+		{Command: "echo", Config: json.RawMessage(`{"shell":"yes"}`)},
+		{Command: "echo", Config: json.RawMessage(`{"workdir":"/srv/app"}`)},
+		{Command: "echo", Config: json.RawMessage(`{"max_output_bytes":-1}`)},
+		{Command: "echo", Config: json.RawMessage(`{"kill_grace":"-1s"}`)},
+		{Command: "echo", Config: json.RawMessage(`{"kill_grace":"31s"}`)},
+		{Command: "echo", Config: json.RawMessage(`{"limits":{"memory_bytes":-1}}`)},
+	}
+	for _, job := range invalid {
+		if err := executor.Validate(job); err == nil {
+			t.Errorf("Expected job with command %q and config %s to be rejected", job.Command, job.Config)
+		}
+	}
+}
+
+func TestExecutor_Validate_RunAs(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{RunAsUsers: []string{"nobody"}, RunAsGroups: []string{"nogroup"}})
+
+	allowed := &types.Job{Command: "id", Config: json.RawMessage(`{"run_as_user":"nobody","run_as_group":"nogroup"}`)}
+	if err := executor.Validate(allowed); err != nil {
+		t.Errorf("Expected allowlisted account to be accepted, got: %v", err)
+	}
+
+	for _, config := range []string{`{"run_as_user":"root"}`, `{"run_as_user":"nobody","run_as_group":"wheel"}`, `{"run_as_group":"nogroup"}`} {
+		job := &types.Job{Command: "id", Config: json.RawMessage(config)}
+		if err := executor.Validate(job); err == nil {
+			t.Errorf("Expected config %s to be rejected", config)
+		}
+	}
+}
+
+func TestExecutor_Execute_RunAsUser(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Running commands as another user needs root")
+	}
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skipf("No nobody user: %v", err)
+	}
+
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{RunAsUsers: []string{"nobody"}})
+
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_run_as",
+		Command: "id -u; echo $USER",
+		Config:  json.RawMessage(`{"shell":true,"run_as_user":"nobody"}`),
+	}
+
+	result := executor.Execute(context.Background(), job, newTestRun(job))
+
+	if result.Status != types.RunStatusSucceeded {
+		t.Fatalf("Expected status %s, got %s: %v", types.RunStatusSucceeded, result.Status, result.Error)
+	}
+
+	if want := nobody.Uid + "\nnobody\n"; result.Stdout != want {
+		t.Errorf("Expected output %q, got %q", want, result.Stdout)
+	}
+}
+
+func TestExecutor_ValidateCommand(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	// Test with command that should exist
+	if err := executor.ValidateCommand("echo"); err != nil {
+		t.Errorf("Expected 'echo' command to be valid, got error: %v", err)
+	}
+
+	// Test with command that shouldn't exist
+	if err := executor.ValidateCommand("nonexistent_command_xyz"); err == nil {
+		t.Error("Expected error for nonexistent command, got nil")
+	}
+}
+
+func TestExecutor_TruncateOutput(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	tests := []struct {
+		name     string
+		output   string
+		maxLen   int
+		expected string
+	}{
+		{
+			name:     "short output",
+			output:   "hello",
+			maxLen:   100,
+			expected: "hello",
+		},
+		{
+			name:     "exact length",
+			output:   "hello",
+			maxLen:   5,
+			expected: "hello",
+		},
+		{
+			name:     "needs truncation",
+			output:   "this is a very long output that should be truncated",
+			maxLen:   10,
+			expected: "this is a ... (truncated)",
+		},
+		{
+			name:     "with whitespace",
+			output:   "\n  hello world  \n",
+			maxLen:   100,
+			expected: "hello world",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := executor.truncateOutput(tt.output, tt.maxLen)
+			if result != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestExecutor_Execute_LargeOutput(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	// Generate large output
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_large_output",
+		Command: "sh",
+		Args:    []string{"-c", "for i in $(seq 1 1000); do echo \"Line $i with some text\"; done"},
+	}
+
+	ctx := context.Background()
+	result := executor.Execute(ctx, job, newTestRun(job))
+
+	if result.Status != types.RunStatusSucceeded {
+		t.Errorf("Expected status %s, got %s", types.RunStatusSucceeded, result.Status)
+	}
+
+	// Output should be captured even if large
+	if len(result.Output) == 0 {
+		t.Error("Expected output to be captured")
+	}
+
+	// Should contain expected content
+	if !strings.Contains(result.Output, "Line 1") || !strings.Contains(result.Output, "Line 1000") {
+		t.Error("Expected output to contain first and last lines")
+	}
+}
+
+// Helper function for comparing output with whitespace differences
+func containsIgnoreWhitespace(output, expected string) bool {
+	// Simple contains check, ignoring exact whitespace
+	return len(output) > 0 && len(expected) > 0 &&
+		(output == expected ||
+			strings.Contains(strings.TrimSpace(output), strings.TrimSpace(expected)))
+}
@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"slices"
+	"strconv"
+)
+
+// runAsAllowed checks a command job's run_as_user and run_as_group against
+// the worker's allowlists. Values are matched exactly as the job writes them.
+func (s Settings) runAsAllowed(cfg *CommandConfig) error {
+	if cfg.RunAsUser != "" && !slices.Contains(s.RunAsUsers, cfg.RunAsUser) {
+		return fmt.Errorf("config.run_as_user '%s' is not allowed: it must be listed in JOB_RUN_AS_USERS", cfg.RunAsUser)
+	}
+	if cfg.RunAsGroup != "" && !slices.Contains(s.RunAsGroups, cfg.RunAsGroup) {
+		return fmt.Errorf("config.run_as_group '%s' is not allowed: it must be listed in JOB_RUN_AS_GROUPS", cfg.RunAsGroup)
+	}
+	return nil
+}
+
+// runAsAccount is the resolved user and group a command runs as
+type runAsAccount struct {
+	user   *user.User // Nil when only the group changes
+	uid    uint32
+	gid    uint32
+	groups []uint32 // Supplementary groups
+}
+
+// owner returns the uid and gid that files made for the command should
+// belong to
+func (a *runAsAccount) owner() (int, int) {
+	uid := os.Geteuid()
+	if a.user != nil {
+		uid = int(a.uid)
+	}
+	return uid, int(a.gid)
+}
+
+// resolveRunAs looks up the account of a job's run_as_user and run_as_group,
+// given by name or numeric ID. The user's primary group is used unless a
+// group is given.
+func resolveRunAs(userName, groupName string) (*runAsAccount, error) {
+	account := &runAsAccount{}
+
+	if userName != "" {
+		u, err := lookupUser(userName)
+		if err != nil {
+			return nil, err
+		}
+		account.user = u
+
+		uid, err := parseID(u.Uid)
+		if err != nil {
+			return nil, fmt.Errorf("user '%s' has unsupported uid '%s'", userName, u.Uid)
+		}
+		gid, err := parseID(u.Gid)
+		if err != nil {
+			return nil, fmt.Errorf("user '%s' has unsupported gid '%s'", userName, u.Gid)
+		}
+		account.uid, account.gid = uid, gid
+
+		groupIDs, err := u.GroupIds()
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up groups of user '%s': %w", userName, err)
+		}
+		for _, id := range groupIDs {
+			if gid, err := parseID(id); err == nil {
+				account.groups = append(account.groups, gid)
+			}
+		}
+	}
+
+	if groupName != "" {
+		g, err := lookupGroup(groupName)
+		if err != nil {
+			return nil, err
+		}
+		gid, err := parseID(g.Gid)
+		if err != nil {
+			return nil, fmt.Errorf("group '%s' has unsupported gid '%s'", groupName, g.Gid)
+		}
+		account.gid = gid
+	}
+
+	return account, nil
+}
+
+// lookupUser finds a user by name, or by uid for numeric values
+func lookupUser(name string) (*user.User, error) {
+	u, err := user.Lookup(name)
+	if err == nil {
+		return u, nil
+	}
+	if _, numErr := strconv.ParseUint(name, 10, 32); numErr == nil {
+		if u, idErr := user.LookupId(name); idErr == nil {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("user '%s' not found on the worker", name)
+}
+
+// lookupGroup finds a group by name, or by gid for numeric values
+func lookupGroup(name string) (*user.Group, error) {
+	g, err := user.LookupGroup(name)
+	if err == nil {
+		return g, nil
+	}
+	if _, numErr := strconv.ParseUint(name, 10, 32); numErr == nil {
+		if g, idErr := user.LookupGroupId(name); idErr == nil {
+			return g, nil
+		}
+	}
+	return nil, fmt.Errorf("group '%s' not found on the worker", name)
+}
+
+// parseID parses a numeric user or group ID
+func parseID(id string) (uint32, error) {
+	n, err := strconv.ParseUint(id, 10, 32)
+	return uint32(n), err
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package executor
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// setCredential fails on platforms without Unix credentials
+func setCredential(cmd *exec.Cmd, account *runAsAccount) error {
+	return fmt.Errorf("run_as_user and run_as_group are only supported on Unix")
+}
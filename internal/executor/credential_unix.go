@@ -0,0 +1,40 @@
+//go:build unix
+
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setCredential makes cmd run as account. Switching to another account
+// needs a worker running as root.
+func setCredential(cmd *exec.Cmd, account *runAsAccount) error {
+	uid, gid := account.owner()
+
+	// Any worker may run jobs as its own user and group
+	if uid == os.Geteuid() && gid == os.Getegid() {
+		return nil
+	}
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("the worker must run as root to run jobs as another user or group")
+	}
+
+	// Drop root's supplementary groups even if the user has none
+	groups := account.groups
+	if groups == nil {
+		groups = []uint32{}
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:    uint32(uid),
+		Gid:    uint32(gid),
+		Groups: groups,
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// Env modes decide which variables a command starts with
+const (
+	EnvModeMerge    = "merge"    // Inherited worker variables, overridden by the job's env
+	EnvModeInherit  = "inherit"  // Inherited worker variables only
+	EnvModeIsolated = "isolated" // The job's env only
+)
+
+// asterEnvPrefix prefixes the variables Aster sets for every command
+const asterEnvPrefix = "ASTER_"
+
+// defaultPath is the PATH of isolated commands whose env doesn't set one
+const defaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// validateEnvMode checks that mode is a known env mode
+func validateEnvMode(mode string) error {
+	switch mode {
+	case EnvModeMerge, EnvModeInherit, EnvModeIsolated:
+		return nil
+	default:
+		return fmt.Errorf("config.env_mode must be one of: %s, %s, %s", EnvModeMerge, EnvModeInherit, EnvModeIsolated)
+	}
+}
+
+// validateEnv checks a command job's env against its env mode
+func validateEnv(mode string, env map[string]string) error {
+	if mode == EnvModeInherit && len(env) > 0 {
+		return fmt.Errorf("env can't be set with env_mode '%s'", EnvModeInherit)
+	}
+
+	for key := range env {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return fmt.Errorf("invalid env variable name '%s'", key)
+		}
+		if strings.HasPrefix(key, asterEnvPrefix) {
+			return fmt.Errorf("env variable '%s' is reserved: names starting with %s are set by Aster", key, asterEnvPrefix)
+		}
+	}
+
+	return nil
+}
+
+// inheritable reports whether a worker variable may be passed to commands
+func (s Settings) inheritable(name string) bool {
+	if len(s.EnvAllowlist) > 0 && !matchesAny(s.EnvAllowlist, name) {
+		return false
+	}
+	return !matchesAny(s.EnvDenylist, name)
+}
+
+// matchesAny reports whether name matches one of the glob patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// buildEnv returns the environment of a run's command. Worker variables
+// pass through the worker's allowlist and denylist, the job's env is applied
+// on top, and the ASTER_ variables describing the run come last. Commands
+// run as another user inherit that user's HOME, USER and LOGNAME instead of
+// the worker's.
+func (s Settings) buildEnv(mode string, job *types.Job, run *types.Run, account *runAsAccount) []string {
+	vars := make(map[string]string)
+
+	if mode != EnvModeIsolated {
+		for _, entry := range os.Environ() {
+			name, value, ok := strings.Cut(entry, "=")
+			if ok && s.inheritable(name) {
+				vars[name] = value
+			}
+		}
+
+		if account != nil && account.user != nil {
+			vars["HOME"] = account.user.HomeDir
+			vars["USER"] = account.user.Username
+			vars["LOGNAME"] = account.user.Username
+		}
+	}
+
+	for key, value := range job.Env {
+		vars[key] = value
+	}
+
+	if _, ok := vars["PATH"]; !ok && mode == EnvModeIsolated {
+		vars["PATH"] = defaultPath
+	}
+
+	vars[asterEnvPrefix+"RUN_ID"] = run.ID.String()
+	vars[asterEnvPrefix+"JOB_ID"] = job.ID.String()
+	vars[asterEnvPrefix+"JOB_NAME"] = job.Name
+	vars[asterEnvPrefix+"ATTEMPT"] = strconv.Itoa(run.AttemptNum)
+	vars[asterEnvPrefix+"SCHEDULED_AT"] = run.ScheduledAt.UTC().Format(time.RFC3339)
+	vars[asterEnvPrefix+"TRIGGER"] = string(run.Trigger)
+
+	env := make([]string, 0, len(vars))
+	for key, value := range vars {
+		env = append(env, key+"="+value)
+	}
+	slices.Sort(env)
+	return env
+}
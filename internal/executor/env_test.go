@@ -0,0 +1,109 @@
+package executor
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+	"github.com/google/uuid"
+)
+
+func TestSettings_BuildEnv(t *testing.T) {
+	t.Setenv("ASTER_TEST_SHARED", "worker")
+	t.Setenv("ASTER_TEST_OTHER", "worker")
+	t.Setenv("POSTGRES_PASSWORD", "secret")
+
+	settings := Settings{EnvDenylist: []string{"POSTGRES_*"}}
+	job := &types.Job{
+		ID:   uuid.New(),
+		Name: "test_env",
+		Env:  map[string]string{"ASTER_TEST_SHARED": "job"},
+	}
+	run := newTestRun(job)
+	run.AttemptNum = 2
+	run.Trigger = types.RunTriggerManual
+	run.ScheduledAt = time.Date(2024, 3, 1, 2, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	runVars := []string{
+		"ASTER_RUN_ID=" + run.ID.String(),
+		"ASTER_JOB_ID=" + job.ID.String(),
+		"ASTER_JOB_NAME=test_env",
+		"ASTER_ATTEMPT=2",
+		"ASTER_SCHEDULED_AT=2024-03-01T01:00:00Z",
+		"ASTER_TRIGGER=manual",
+	}
+
+	tests := []struct {
+		name    string
+		mode    string
+		include []string
+		exclude []string
+	}{
+		{
+			name:    "merge",
+			mode:    EnvModeMerge,
+			include: append([]string{"ASTER_TEST_SHARED=job", "ASTER_TEST_OTHER=worker"}, runVars...),
+			exclude: []string{"ASTER_TEST_SHARED=worker", "POSTGRES_PASSWORD=secret"},
+		},
+		{
+			name:    "isolated",
+			mode:    EnvModeIsolated,
+			include: append([]string{"ASTER_TEST_SHARED=job", "PATH=" + defaultPath}, runVars...),
+			exclude: []string{"ASTER_TEST_OTHER=worker", "POSTGRES_PASSWORD=secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := settings.buildEnv(tt.mode, job, run, nil)
+
+			for _, entry := range tt.include {
+				if !slices.Contains(env, entry) {
+					t.Errorf("Expected env to contain %s, got %v", entry, env)
+				}
+			}
+			for _, entry := range tt.exclude {
+				if slices.Contains(env, entry) {
+					t.Errorf("Expected env not to contain %s", entry)
+				}
+			}
+		})
+	}
+
+	// An allowlist limits inheritance to the variables it names
+	settings = Settings{EnvAllowlist: []string{"ASTER_TEST_OTHER"}}
+	env := settings.buildEnv(EnvModeInherit, &types.Job{ID: job.ID, Name: job.Name}, run, nil)
+	if !slices.Contains(env, "ASTER_TEST_OTHER=worker") || slices.Contains(env, "ASTER_TEST_SHARED=worker") {
+		t.Errorf("Expected only allowlisted variables to be inherited, got %v", env)
+	}
+}
+
+func TestValidateEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{"merge with env", EnvModeMerge, map[string]string{"APP_ENV": "prod"}, false},
+		{"isolated with env", EnvModeIsolated, map[string]string{"PATH": "/opt/bin"}, false},
+		{"inherit without env", EnvModeInherit, nil, false},
+		{"inherit with env", EnvModeInherit, map[string]string{"APP_ENV": "prod"}, true},
+		{"reserved name", EnvModeMerge, map[string]string{"ASTER_RUN_ID": "x"}, true},
+		{"invalid name", EnvModeMerge, map[string]string{"A=B": "x"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateEnv(tt.mode, tt.env)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := validateEnvMode("replace"); err == nil {
+		t.Error("Expected unknown env mode to be rejected")
+	}
+}
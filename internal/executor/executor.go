@@ -1,120 +1,88 @@
 package executor
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"os/exec"
-	"strings"
+	"maps"
 	"time"
 
-	"go.uber.org/zap"
-
 	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
 )
 
+// ExecutionResult is the outcome of executing a single run
 type ExecutionResult struct {
-	Status    types.RunStatus
-	Output    string
-	Error     error
-	StartTime time.Time
-	EndTime   time.Time
-	Duration  time.Duration
+	Status          types.RunStatus
+	Output          string
+	Stdout          string          // Set by executors that run a process
+	Stderr          string          // Set by executors that run a process
+	ExitCode        *int            // Set when a process exited by itself
+	Signal          string          // Signal that ended a process, such as SIGTERM
+	OutputTruncated bool            // Whether the middle of long output was dropped
+	Result          json.RawMessage // JSON object the job reported about its run
+	Error           error
+	StartTime       time.Time
+	EndTime         time.Time
+	Duration        time.Duration
 }
 
-// Executor handles running jobs locally
-type Executor struct {
-	logger *zap.Logger
+// Settings are worker-level settings that apply to every run of a worker
+type Settings struct {
+	EnvAllowlist []string // Patterns of worker variables commands may inherit; empty allows all
+	EnvDenylist  []string // Patterns of worker variables commands never inherit
+	CgroupPath   string   // Delegated cgroup v2 directory that runs with limits get a group in; empty disables cgroups
+	RunAsUsers   []string // Users command jobs may run as
+	RunAsGroups  []string // Groups command jobs may run as
 }
 
-// NewExecutor creates a new job executor
-func NewExecutor(logger *zap.Logger) *Executor {
-	return &Executor{
-		logger: logger,
-	}
+// Executor runs jobs of one type. Cancelling ctx must stop the run and
+// report it as cancelled.
+type Executor interface {
+	Execute(ctx context.Context, job *types.Job, run *types.Run) *ExecutionResult
 }
 
-// Execute runs a job and returns the result
-func (e *Executor) Execute(ctx context.Context, job *types.Job) *ExecutionResult {
-	result := &ExecutionResult{
-		StartTime: time.Now(),
-		Status:    types.RunStatusRunning,
-	}
-
-	e.logger.Info("Starting job execution",
-		zap.String("job_id", job.ID.String()),
-		zap.String("job_name", job.Name),
-		zap.String("command", job.Command),
-		zap.Strings("args", job.Args))
-
-	// Create command context with timeout if specified
-	cmdCtx := ctx
-	if job.Timeout != nil {
-		var cancel context.CancelFunc
-		cmdCtx, cancel = context.WithTimeout(ctx, *job.Timeout)
-		defer cancel()
-	}
-
-	// Create the command
-	cmd := exec.CommandContext(cmdCtx, job.Command, job.Args...)
+// Validator is implemented by executors that can reject a job's
+// configuration before it is saved
+type Validator interface {
+	Validate(job *types.Job) error
+}
 
-	// Set environment variables
-	if len(job.Env) > 0 {
-		cmd.Env = make([]string, 0, len(job.Env))
-		for key, value := range job.Env {
-			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
-		}
+// decodeConfig reads a job's type-specific config into v, rejecting fields
+// the executor doesn't know so misspelt settings aren't silently ignored
+func decodeConfig(job *types.Job, v any) error {
+	if len(job.Config) == 0 {
+		return nil
 	}
 
-	// Run the command and capture output
-	output, err := cmd.CombinedOutput()
-	result.EndTime = time.Now()
-	result.Duration = result.EndTime.Sub(result.StartTime)
-	result.Output = string(output)
-
-	// Determine the result status
-	if err != nil {
-		if cmdCtx.Err() == context.DeadlineExceeded {
-			result.Status = types.RunStatusTimedOut
-			result.Error = fmt.Errorf("job timed out after %s", job.Timeout)
-		} else if cmdCtx.Err() == context.Canceled {
-			result.Status = types.RunStatusCancelled
-			result.Error = fmt.Errorf("job was cancelled")
-		} else {
-			result.Status = types.RunStatusFailed
-			result.Error = fmt.Errorf("command failed: %w", err)
-		}
-	} else {
-		result.Status = types.RunStatusSucceeded
+	decoder := json.NewDecoder(bytes.NewReader(job.Config))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
 	}
-
-	e.logger.Info("Job execution completed",
-		zap.String("job_id", job.ID.String()),
-		zap.String("job_name", job.Name),
-		zap.String("status", string(result.Status)),
-		zap.Duration("duration", result.Duration),
-		zap.String("output_preview", e.truncateOutput(result.Output, 200)))
-
-	return result
+	return nil
 }
 
-// truncateOutput limits output length for logging
-func (e *Executor) truncateOutput(output string, maxLen int) string {
-	// Remove leading/trailing whitespace and newlines
-	output = strings.TrimSpace(output)
-
-	if len(output) <= maxLen {
-		return output
+// ApplyOverrides returns the job as it should run for a single run, leaving
+// the stored job untouched
+func ApplyOverrides(job *types.Job, overrides *types.RunOverrides) *types.Job {
+	if overrides == nil {
+		return job
 	}
 
-	return output[:maxLen] + "... (truncated)"
-}
-
-// ValidateCommand checks if a command is likely to work before executing it
-func (e *Executor) ValidateCommand(command string) error {
-	// Check if command exists in PATH
-	_, err := exec.LookPath(command)
-	if err != nil {
-		return fmt.Errorf("command '%s' not found in PATH: %w", command, err)
+	effective := *job
+	if overrides.Args != nil {
+		effective.Args = overrides.Args
 	}
-	return nil
+	if len(overrides.Env) > 0 {
+		effective.Env = make(map[string]string, len(job.Env)+len(overrides.Env))
+		maps.Copy(effective.Env, job.Env)
+		maps.Copy(effective.Env, overrides.Env)
+	}
+	if overrides.Timeout != nil {
+		timeout := *overrides.Timeout
+		effective.Timeout = &timeout
+	}
+
+	return &effective
 }
@@ -0,0 +1,262 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// TypeHTTP sends a single HTTP request, such as a webhook call
+const TypeHTTP = "http"
+
+const (
+	// maxResponseBody bounds how much of a response is read for assertions
+	maxResponseBody = 1 << 20
+
+	// maxResponseOutput bounds how much of a response body is kept as output
+	maxResponseOutput = 64 << 10
+)
+
+func init() {
+	Register(TypeHTTP, func(logger *zap.Logger, _ Settings) Executor {
+		return NewHTTPExecutor(logger)
+	})
+}
+
+// HTTPConfig is the config of an http job
+type HTTPConfig struct {
+	Method         string            `json:"method,omitempty"` // Defaults to GET
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           string            `json:"body,omitempty"`            // Template rendered for each run
+	ExpectedStatus []int             `json:"expected_status,omitempty"` // Defaults to any 2xx status
+	Timeout        types.Duration    `json:"timeout,omitempty"`         // Limits the request on top of the job timeout
+	BodyContains   []string          `json:"body_contains,omitempty"`   // Text the response body must contain
+	BodyMatches    string            `json:"body_matches,omitempty"`    // Regular expression the response body must match
+}
+
+// HTTPExecutor handles running http jobs
+type HTTPExecutor struct {
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewHTTPExecutor creates a new http executor
+func NewHTTPExecutor(logger *zap.Logger) *HTTPExecutor {
+	return &HTTPExecutor{
+		client: &http.Client{},
+		logger: logger,
+	}
+}
+
+// parseHTTPConfig reads and checks an http job's config
+func parseHTTPConfig(job *types.Job) (*HTTPConfig, error) {
+	var cfg HTTPConfig
+	if err := decodeConfig(job, &cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("config.url is required")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config.url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("config.url must be an absolute http or https URL")
+	}
+
+	if cfg.Method == "" {
+		cfg.Method = http.MethodGet
+	}
+	cfg.Method = strings.ToUpper(cfg.Method)
+	if strings.ContainsAny(cfg.Method, " \t\r\n") {
+		return nil, fmt.Errorf("invalid config.method '%s'", cfg.Method)
+	}
+
+	for _, status := range cfg.ExpectedStatus {
+		if status < 100 || status > 599 {
+			return nil, fmt.Errorf("config.expected_status contains invalid status %d", status)
+		}
+	}
+
+	if cfg.Timeout < 0 {
+		return nil, fmt.Errorf("config.timeout must not be negative")
+	}
+
+	if cfg.BodyMatches != "" {
+		if _, err := regexp.Compile(cfg.BodyMatches); err != nil {
+			return nil, fmt.Errorf("invalid config.body_matches: %w", err)
+		}
+	}
+
+	if _, err := parseTemplate("body", cfg.Body); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// Validate checks an http job's config
+func (e *HTTPExecutor) Validate(job *types.Job) error {
+	_, err := parseHTTPConfig(job)
+	return err
+}
+
+// Execute sends the job's request and checks the response
+func (e *HTTPExecutor) Execute(ctx context.Context, job *types.Job, run *types.Run) *ExecutionResult {
+	result := &ExecutionResult{
+		StartTime: time.Now(),
+		Status:    types.RunStatusRunning,
+	}
+
+	finish := func(status types.RunStatus, err error) *ExecutionResult {
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(result.StartTime)
+		result.Status = status
+		result.Error = err
+
+		e.logger.Info("Job execution completed",
+			zap.String("job_id", job.ID.String()),
+			zap.String("job_name", job.Name),
+			zap.String("status", string(result.Status)),
+			zap.Duration("duration", result.Duration))
+		return result
+	}
+
+	cfg, err := parseHTTPConfig(job)
+	if err != nil {
+		return finish(types.RunStatusFailed, err)
+	}
+
+	body, err := renderTemplate("body", cfg.Body, newTemplateData(job, run))
+	if err != nil {
+		return finish(types.RunStatusFailed, err)
+	}
+
+	e.logger.Info("Starting job execution",
+		zap.String("job_id", job.ID.String()),
+		zap.String("job_name", job.Name),
+		zap.String("method", cfg.Method),
+		zap.String("url", cfg.URL))
+
+	// The job timeout and the request timeout both bound the request
+	reqCtx := ctx
+	if job.Timeout != nil {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(reqCtx, time.Duration(*job.Timeout))
+		defer cancel()
+	}
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(reqCtx, time.Duration(cfg.Timeout))
+		defer cancel()
+	}
+
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(reqCtx, cfg.Method, cfg.URL, bodyReader)
+	if err != nil {
+		return finish(types.RunStatusFailed, fmt.Errorf("failed to create request: %w", err))
+	}
+	for key, value := range cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	// checkStopped reports a request that ended because it ran out of time
+	// or its run was cancelled
+	checkStopped := func() *ExecutionResult {
+		if ctx.Err() != nil {
+			return finish(types.RunStatusCancelled, fmt.Errorf("job was cancelled"))
+		}
+		if errors.Is(reqCtx.Err(), context.DeadlineExceeded) {
+			return finish(types.RunStatusTimedOut, fmt.Errorf("request timed out"))
+		}
+		return nil
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		if stopped := checkStopped(); stopped != nil {
+			return stopped
+		}
+		return finish(types.RunStatusFailed, fmt.Errorf("request failed: %w", err))
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	result.Output = formatResponse(resp, respBody)
+	if err != nil {
+		if stopped := checkStopped(); stopped != nil {
+			return stopped
+		}
+		return finish(types.RunStatusFailed, fmt.Errorf("failed to read response: %w", err))
+	}
+
+	if !statusExpected(resp.StatusCode, cfg.ExpectedStatus) {
+		return finish(types.RunStatusFailed, fmt.Errorf("unexpected response status %d", resp.StatusCode))
+	}
+
+	for _, text := range cfg.BodyContains {
+		if !strings.Contains(string(respBody), text) {
+			return finish(types.RunStatusFailed, fmt.Errorf("response body does not contain %q", text))
+		}
+	}
+
+	if cfg.BodyMatches != "" && !regexp.MustCompile(cfg.BodyMatches).Match(respBody) {
+		return finish(types.RunStatusFailed, fmt.Errorf("response body does not match %q", cfg.BodyMatches))
+	}
+
+	return finish(types.RunStatusSucceeded, nil)
+}
+
+// statusExpected reports whether status is one of expected, or any 2xx
+// status when none are listed
+func statusExpected(status int, expected []int) bool {
+	if len(expected) == 0 {
+		return status >= 200 && status < 300
+	}
+	return slices.Contains(expected, status)
+}
+
+// formatResponse renders a response's status line, headers and the start of
+// its body as run output
+func formatResponse(resp *http.Response, body []byte) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s\n", resp.Proto, resp.Status)
+
+	keys := make([]string, 0, len(resp.Header))
+	for key := range resp.Header {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		for _, value := range resp.Header[key] {
+			fmt.Fprintf(&sb, "%s: %s\n", key, value)
+		}
+	}
+	sb.WriteString("\n")
+
+	if len(body) > maxResponseOutput {
+		sb.Write(body[:maxResponseOutput])
+		sb.WriteString("\n... (truncated)")
+	} else {
+		sb.Write(body)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,166 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+	"github.com/google/uuid"
+)
+
+// newHTTPJob returns an http job with the given config
+func newHTTPJob(t *testing.T, cfg HTTPConfig) *types.Job {
+	t.Helper()
+
+	config, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Failed to marshal config: %v", err)
+	}
+	return &types.Job{ID: uuid.New(), Name: "test_http", Type: TypeHTTP, Config: config}
+}
+
+func TestHTTPExecutor_Execute_Success(t *testing.T) {
+	var gotMethod, gotBody, gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotMethod, gotBody, gotHeader = r.Method, string(body), r.Header.Get("X-Token")
+		w.Header().Set("X-Result", "done")
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"status":"queued"}`)
+	}))
+	defer server.Close()
+
+	executor := NewHTTPExecutor(zaptest.NewLogger(t))
+	job := newHTTPJob(t, HTTPConfig{
+		Method:       "post",
+		URL:          server.URL,
+		Headers:      map[string]string{"X-Token": "secret"},
+		Body:         `{"job":"{{.Job.Name}}","attempt":{{.Attempt}}}`,
+		BodyContains: []string{"queued"},
+		BodyMatches:  `"status":\s*"\w+"`,
+	})
+
+	result := executor.Execute(context.Background(), job, newTestRun(job))
+
+	if result.Status != types.RunStatusSucceeded {
+		t.Fatalf("Expected status %s, got %s: %v", types.RunStatusSucceeded, result.Status, result.Error)
+	}
+
+	if gotMethod != http.MethodPost || gotHeader != "secret" {
+		t.Errorf("Expected POST with X-Token header, got %s with %q", gotMethod, gotHeader)
+	}
+
+	if gotBody != `{"job":"test_http","attempt":1}` {
+		t.Errorf("Expected rendered body, got %q", gotBody)
+	}
+
+	for _, want := range []string{"202 Accepted", "X-Result: done", `{"status":"queued"}`} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("Expected output to contain %q, got: %q", want, result.Output)
+		}
+	}
+}
+
+func TestHTTPExecutor_Execute_Failures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		cfg  HTTPConfig
+	}{
+		{"unexpected status", HTTPConfig{URL: server.URL + "/missing"}},
+		{"status not listed", HTTPConfig{URL: server.URL, ExpectedStatus: []int{201}}},
+		{"body missing text", HTTPConfig{URL: server.URL, BodyContains: []string{"done"}}},
+		{"body not matching", HTTPConfig{URL: server.URL, BodyMatches: "^OK$"}},
+		{"unreachable", HTTPConfig{URL: "http://127.0.0.1:1"}},
+	}
+
+	executor := NewHTTPExecutor(zaptest.NewLogger(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := newHTTPJob(t, tt.cfg)
+			result := executor.Execute(context.Background(), job, newTestRun(job))
+
+			if result.Status != types.RunStatusFailed || result.Error == nil {
+				t.Errorf("Expected status %s with an error, got %s %v", types.RunStatusFailed, result.Status, result.Error)
+			}
+		})
+	}
+}
+
+func TestHTTPExecutor_Execute_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	executor := NewHTTPExecutor(zaptest.NewLogger(t))
+	job := newHTTPJob(t, HTTPConfig{URL: server.URL, Timeout: types.Duration(100 * time.Millisecond)})
+
+	start := time.Now()
+	result := executor.Execute(context.Background(), job, newTestRun(job))
+
+	if result.Status != types.RunStatusTimedOut {
+		t.Errorf("Expected status %s, got %s", types.RunStatusTimedOut, result.Status)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the request to stop near its timeout, took %s", elapsed)
+	}
+
+	// Cancelling the run stops the request too
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	job = newHTTPJob(t, HTTPConfig{URL: server.URL})
+	result = executor.Execute(ctx, job, newTestRun(job))
+
+	if result.Status != types.RunStatusCancelled {
+		t.Errorf("Expected status %s, got %s", types.RunStatusCancelled, result.Status)
+	}
+}
+
+func TestHTTPExecutor_Validate(t *testing.T) {
+	executor := NewHTTPExecutor(zaptest.NewLogger(t))
+
+	valid := `{"method":"POST","url":"https://example.com/hook","expected_status":[200,204]}`
+	job := &types.Job{Type: TypeHTTP, Config: json.RawMessage(valid)}
+	if err := executor.Validate(job); err != nil {
+		t.Errorf("Expected valid config, got: %v", err)
+	}
+
+	invalid := []string{
+		`{}`,
+		`{"url":"example.com/hook"}`,
+		`{"url":"ftp://example.com"}`,
+		`{"url":"https://example.com","expected_status":[42]}`,
+		`{"url":"https://example.com","body_matches":"("}`,
+		`{"url":"https://example.com","body":"{{.Job.Name"}`,
+		`{"url":"https://example.com","method":"BAD METHOD"}`,
+		`{"url":"https://example.com","headres":{}}`,
+	}
+	for _, config := range invalid {
+		job := &types.Job{Type: TypeHTTP, Config: json.RawMessage(config)}
+		if err := executor.Validate(job); err == nil {
+			t.Errorf("Expected config %s to be rejected", config)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// ResourceLimits caps what a command may use. Zero leaves a resource
+// unlimited.
+type ResourceLimits struct {
+	MemoryBytes int64          `json:"memory_bytes,omitempty"` // Address space per process, and memory of the whole command in a cgroup
+	CPUTime     types.Duration `json:"cpu_time,omitempty"`     // CPU time per process, rounded up to whole seconds
+	OpenFiles   int            `json:"open_files,omitempty"`   // Open files per process
+	Processes   int            `json:"processes,omitempty"`    // Processes of the run_as_user account, or of the whole command in a cgroup
+}
+
+// set reports whether any limit is set
+func (l ResourceLimits) set() bool {
+	return l != ResourceLimits{}
+}
+
+// validate checks that no limit is negative
+func (l ResourceLimits) validate() error {
+	if l.MemoryBytes < 0 || l.CPUTime < 0 || l.OpenFiles < 0 || l.Processes < 0 {
+		return fmt.Errorf("config.limits must not be negative")
+	}
+	return nil
+}
+
+// cpuSeconds returns the CPU time limit in whole seconds, rounded up
+func (l ResourceLimits) cpuSeconds() uint64 {
+	return uint64((time.Duration(l.CPUTime) + time.Second - 1) / time.Second)
+}
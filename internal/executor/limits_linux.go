@@ -0,0 +1,322 @@
+package executor
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/google/uuid"
+	"golang.org/x/sys/unix"
+)
+
+// rlimitExecEnv carries a wrapped command's path and limits to the worker
+// binary that execs it
+const rlimitExecEnv = "ASTER_RLIMIT_EXEC"
+
+// rlimitStatusFD is where the worker binary reports that it couldn't limit
+// or start a wrapped command. It is the first of the command's ExtraFiles.
+const rlimitStatusFD = 3
+
+// rlimitExec is what the worker binary needs to limit and exec a command
+type rlimitExec struct {
+	Path   string         `json:"path"`
+	Limits ResourceLimits `json:"limits"`
+}
+
+// A worker binary started by wrapRlimits limits itself and execs the command
+// in place of the rest of the program
+func init() {
+	if spec, ok := os.LookupEnv(rlimitExecEnv); ok {
+		execWithRlimits(spec)
+	}
+}
+
+// wrapRlimits makes cmd start through the worker binary, which sets limits
+// on itself and then execs the command, so they are in place before the
+// command runs. The returned function must be called once cmd.Start has
+// returned, and reports why the command couldn't be limited or started.
+func wrapRlimits(cmd *exec.Cmd, limits ResourceLimits) (func() error, error) {
+	if cmd.Err != nil || len(cmd.ExtraFiles) > 0 {
+		return nil, fmt.Errorf("resource limits can't be applied to this command")
+	}
+
+	spec, err := json.Marshal(rlimitExec{Path: cmd.Path, Limits: limits})
+	if err != nil {
+		return nil, err
+	}
+	status, statusW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create status pipe: %w", err)
+	}
+
+	cmd.Path = "/proc/self/exe"
+	cmd.Env = append(cmd.Env, rlimitExecEnv+"="+string(spec))
+	cmd.ExtraFiles = []*os.File{statusW}
+
+	// The status pipe closes without a word once the command has been
+	// exec'd, as the worker binary marks it close-on-exec
+	return func() error {
+		statusW.Close()
+		defer status.Close()
+
+		msg, _ := io.ReadAll(status)
+		if len(msg) > 0 {
+			return errors.New(string(msg))
+		}
+		return nil
+	}, nil
+}
+
+// execWithRlimits sets limits on the current process and execs the command
+// described by spec. It only returns by exiting, after reporting why it
+// failed on the status pipe.
+func execWithRlimits(spec string) {
+	syscall.CloseOnExec(rlimitStatusFD)
+	fail := func(err error) {
+		syscall.Write(rlimitStatusFD, []byte(err.Error()))
+		os.Exit(126)
+	}
+
+	var req rlimitExec
+	if err := json.Unmarshal([]byte(spec), &req); err != nil {
+		fail(fmt.Errorf("invalid %s: %w", rlimitExecEnv, err))
+	}
+
+	env := slices.DeleteFunc(os.Environ(), func(entry string) bool {
+		return strings.HasPrefix(entry, rlimitExecEnv+"=")
+	})
+
+	// Everything execve needs is allocated up front, since a low memory
+	// limit can leave no room for more
+	path, err := syscall.BytePtrFromString(req.Path)
+	if err != nil {
+		fail(err)
+	}
+	argv, err := syscall.SlicePtrFromStrings(os.Args)
+	if err != nil {
+		fail(err)
+	}
+	envv, err := syscall.SlicePtrFromStrings(env)
+	if err != nil {
+		fail(err)
+	}
+
+	if err := setRlimits(req.Limits); err != nil {
+		fail(fmt.Errorf("failed to apply resource limits: %w", err))
+	}
+
+	_, _, errno := syscall.RawSyscall(syscall.SYS_EXECVE,
+		uintptr(unsafe.Pointer(path)),
+		uintptr(unsafe.Pointer(&argv[0])),
+		uintptr(unsafe.Pointer(&envv[0])))
+	fail(fmt.Errorf("failed to start %s: %w", req.Path, errno))
+}
+
+// setRlimits sets limits on the current process. The memory limit comes
+// last, as the process can't allocate much once it is set.
+func setRlimits(limits ResourceLimits) error {
+	set := func(name string, resource int, soft, hard uint64) error {
+		// syscall.Setrlimit also stops Go from restoring its own open
+		// files limit on exec
+		if err := syscall.Setrlimit(resource, &syscall.Rlimit{Cur: soft, Max: hard}); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	}
+
+	if limits.CPUTime > 0 {
+		// The soft limit sends SIGXCPU, and the hard limit a second later
+		// SIGKILL for processes that ignore it
+		seconds := limits.cpuSeconds()
+		if err := set("cpu_time", unix.RLIMIT_CPU, seconds, seconds+1); err != nil {
+			return err
+		}
+	}
+	if limits.OpenFiles > 0 {
+		if err := set("open_files", unix.RLIMIT_NOFILE, uint64(limits.OpenFiles), uint64(limits.OpenFiles)); err != nil {
+			return err
+		}
+	}
+	if limits.Processes > 0 {
+		if err := set("processes", unix.RLIMIT_NPROC, uint64(limits.Processes), uint64(limits.Processes)); err != nil {
+			return err
+		}
+	}
+	if limits.MemoryBytes > 0 {
+		if err := set("memory_bytes", unix.RLIMIT_AS, uint64(limits.MemoryBytes), uint64(limits.MemoryBytes)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exceeded returns an error naming the limit a failed command ran into, or
+// nil if it isn't known to have hit one. Running out of open files, or of
+// memory and processes outside a cgroup, surfaces as an ordinary failure of
+// the command.
+func (l ResourceLimits) exceeded(state *os.ProcessState, cgroup *runCgroup) error {
+	if state == nil || state.Success() {
+		return nil
+	}
+
+	if l.CPUTime > 0 {
+		status, _ := state.Sys().(syscall.WaitStatus)
+		used := state.UserTime() + state.SystemTime()
+		if (status.Signaled() && status.Signal() == syscall.SIGXCPU) || used >= time.Duration(l.cpuSeconds())*time.Second {
+			return fmt.Errorf("job exceeded its CPU time limit of %ds", l.cpuSeconds())
+		}
+	}
+
+	if cgroup != nil {
+		if l.MemoryBytes > 0 && cgroup.event("memory.events", "oom_kill") > 0 {
+			return fmt.Errorf("job exceeded its memory limit of %d bytes and was killed", l.MemoryBytes)
+		}
+		if l.Processes > 0 && cgroup.event("pids.events", "max") > 0 {
+			return fmt.Errorf("job reached its limit of %d processes", l.Processes)
+		}
+	}
+
+	return nil
+}
+
+// runCgroup is a cgroup v2 group holding a single run's processes
+type runCgroup struct {
+	path string
+	dir  *os.File
+}
+
+// newRunCgroup creates a cgroup for a run under parent, a cgroup v2
+// directory delegated to the worker, and sets the run's limits on it
+func newRunCgroup(parent string, runID uuid.UUID, limits ResourceLimits) (*runCgroup, error) {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(parent, &fs); err != nil {
+		return nil, fmt.Errorf("failed to read cgroup %s: %w", parent, err)
+	}
+	if fs.Type != unix.CGROUP2_SUPER_MAGIC {
+		return nil, fmt.Errorf("%s is not a cgroup v2 directory", parent)
+	}
+
+	// Enabling controllers is idempotent, so every run makes sure they're on
+	err := writeCgroupFile(filepath.Join(parent, "cgroup.subtree_control"), "+memory +pids")
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable cgroup controllers: %w", err)
+	}
+
+	cg := &runCgroup{path: filepath.Join(parent, "run-"+runID.String())}
+	if err := os.Mkdir(cg.path, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cgroup: %w", err)
+	}
+
+	if err := cg.setLimits(limits); err != nil {
+		cg.remove()
+		return nil, err
+	}
+
+	cg.dir, err = os.Open(cg.path)
+	if err != nil {
+		cg.remove()
+		return nil, fmt.Errorf("failed to open cgroup: %w", err)
+	}
+
+	return cg, nil
+}
+
+// setLimits writes the limits that cgroups enforce for the whole command
+func (cg *runCgroup) setLimits(limits ResourceLimits) error {
+	if limits.MemoryBytes > 0 {
+		if err := cg.write("memory.max", strconv.FormatInt(limits.MemoryBytes, 10)); err != nil {
+			return err
+		}
+		// Keep the command from swapping its way past the limit, where swap
+		// accounting is enabled
+		if err := cg.write("memory.swap.max", "0"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if limits.Processes > 0 {
+		if err := cg.write("pids.max", strconv.Itoa(limits.Processes)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write sets one of the cgroup's interface files
+func (cg *runCgroup) write(file, value string) error {
+	if err := writeCgroupFile(filepath.Join(cg.path, file), value); err != nil {
+		return fmt.Errorf("failed to set cgroup %s: %w", file, err)
+	}
+	return nil
+}
+
+// writeCgroupFile writes an existing cgroup interface file
+func writeCgroupFile(path, value string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(value); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// attach makes cmd start inside the cgroup
+func (cg *runCgroup) attach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(cg.dir.Fd())
+}
+
+// event returns a counter from one of the cgroup's events files
+func (cg *runCgroup) event(file, key string) int {
+	f, err := os.Open(filepath.Join(cg.path, file))
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, value, _ := strings.Cut(scanner.Text(), " ")
+		if name == key {
+			count, _ := strconv.Atoi(value)
+			return count
+		}
+	}
+	return 0
+}
+
+// remove kills anything left in the cgroup and deletes it
+func (cg *runCgroup) remove() {
+	if cg.dir != nil {
+		cg.dir.Close()
+	}
+
+	// cgroup.kill needs Linux 5.14; older kernels leave leftovers to the
+	// process group kill
+	cg.write("cgroup.kill", "1")
+
+	// Killed processes take a moment to leave the group
+	for range 20 {
+		if err := os.Remove(cg.path); err == nil || errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+	"github.com/google/uuid"
+)
+
+func TestExecutor_Execute_AppliesRlimits(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_rlimits",
+		Command: "ulimit -n; ulimit -t",
+		Config:  json.RawMessage(`{"shell":true,"limits":{"open_files":64,"cpu_time":"1.5s"}}`),
+	}
+
+	result := executor.Execute(context.Background(), job, newTestRun(job))
+
+	if result.Status != types.RunStatusSucceeded {
+		t.Fatalf("Expected status %s, got %s: %v", types.RunStatusSucceeded, result.Status, result.Error)
+	}
+
+	// CPU time is rounded up to whole seconds
+	if result.Stdout != "64\n2\n" {
+		t.Errorf("Expected the open files and CPU time limits to be set, got %q", result.Stdout)
+	}
+}
+
+func TestExecutor_Execute_CPUTimeExceeded(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	timeout := types.Duration(10 * time.Second)
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_cpu_limit",
+		Command: "while :; do :; done",
+		Timeout: &timeout,
+		Config:  json.RawMessage(`{"shell":true,"limits":{"cpu_time":"1s"}}`),
+	}
+
+	result := executor.Execute(context.Background(), job, newTestRun(job))
+
+	if result.Status != types.RunStatusLimitExceeded {
+		t.Fatalf("Expected status %s, got %s: %v", types.RunStatusLimitExceeded, result.Status, result.Error)
+	}
+
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "CPU time limit") {
+		t.Errorf("Expected an error naming the CPU time limit, got: %v", result.Error)
+	}
+}
+
+func TestExecutor_Execute_RlimitsFailure(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	executor := NewCommandExecutor(logger, Settings{})
+
+	// No process may open more files than fs.nr_open, root included
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_rlimits_failure",
+		Command: "true",
+		Config:  json.RawMessage(`{"limits":{"open_files":1073741824}}`),
+	}
+
+	result := executor.Execute(context.Background(), job, newTestRun(job))
+
+	if result.Status != types.RunStatusFailed {
+		t.Fatalf("Expected status %s, got %s", types.RunStatusFailed, result.Status)
+	}
+
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "failed to apply resource limits: open_files") {
+		t.Errorf("Expected an error naming the open files limit, got: %v", result.Error)
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !linux
+
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/google/uuid"
+)
+
+// wrapRlimits fails on platforms other than Linux
+func wrapRlimits(cmd *exec.Cmd, limits ResourceLimits) (func() error, error) {
+	return nil, fmt.Errorf("resource limits are only supported on Linux")
+}
+
+// exceeded can't tell which limit a command ran into on other platforms
+func (l ResourceLimits) exceeded(state *os.ProcessState, cgroup *runCgroup) error {
+	return nil
+}
+
+// runCgroup is unused on platforms without cgroups
+type runCgroup struct{}
+
+// newRunCgroup fails on platforms without cgroups
+func newRunCgroup(parent string, runID uuid.UUID, limits ResourceLimits) (*runCgroup, error) {
+	return nil, fmt.Errorf("cgroups are only supported on Linux")
+}
+
+func (cg *runCgroup) attach(cmd *exec.Cmd) {}
+
+func (cg *runCgroup) remove() {}
@@ -0,0 +1,121 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// defaultMaxOutputBytes is how much of each output stream is kept by default
+const defaultMaxOutputBytes = 1 << 20
+
+// maxOutputBytesLimit bounds the max_output_bytes a job may ask for
+const maxOutputBytesLimit = 16 << 20
+
+// LogWriter receives a run's output while it is produced, for example to
+// store it for live viewing. WriteLog must not keep data after returning.
+type LogWriter interface {
+	WriteLog(stream string, data []byte)
+}
+
+// logWriterKey is the context key of a run's LogWriter
+type logWriterKey struct{}
+
+// WithLogWriter returns a context that makes executors copy the output of
+// the run they execute to logs
+func WithLogWriter(ctx context.Context, logs LogWriter) context.Context {
+	return context.WithValue(ctx, logWriterKey{}, logs)
+}
+
+// logWriterFrom returns the LogWriter set on ctx, if any
+func logWriterFrom(ctx context.Context) LogWriter {
+	logs, _ := ctx.Value(logWriterKey{}).(LogWriter)
+	return logs
+}
+
+// streamWriter writes to one stream of a LogWriter
+type streamWriter struct {
+	logs   LogWriter
+	stream string
+}
+
+func (w streamWriter) Write(p []byte) (int, error) {
+	w.logs.WriteLog(w.stream, p)
+	return len(p), nil
+}
+
+// headTailBuffer keeps the first and last bytes written to it, up to limit
+// bytes in total, and drops the middle of longer output. Memory use stays
+// bounded however much is written. It is safe for concurrent writes, so
+// stdout and stderr can share one.
+type headTailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	head  []byte
+	tail  []byte
+	total int64
+}
+
+// newHeadTailBuffer creates a buffer that keeps at most limit bytes
+func newHeadTailBuffer(limit int) *headTailBuffer {
+	return &headTailBuffer{limit: limit}
+}
+
+// headLimit is how many of the first bytes are kept
+func (b *headTailBuffer) headLimit() int {
+	return b.limit / 2
+}
+
+// tailLimit is how many of the last bytes are kept
+func (b *headTailBuffer) tailLimit() int {
+	return b.limit - b.headLimit()
+}
+
+// Write records p, keeping it only if it falls in the head or tail
+func (b *headTailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.total += int64(len(p))
+
+	rest := p
+	if room := b.headLimit() - len(b.head); room > 0 {
+		n := min(room, len(rest))
+		b.head = append(b.head, rest[:n]...)
+		rest = rest[n:]
+	}
+
+	// Let the tail grow to twice its limit before dropping its start, so
+	// bytes are only copied once per tailLimit written
+	b.tail = append(b.tail, rest...)
+	if len(b.tail) > 2*b.tailLimit() {
+		b.tail = append([]byte(nil), b.tail[len(b.tail)-b.tailLimit():]...)
+	}
+
+	return len(p), nil
+}
+
+// Truncated reports whether any output was dropped
+func (b *headTailBuffer) Truncated() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.total > int64(b.limit)
+}
+
+// String returns the kept output, marking where bytes were dropped
+func (b *headTailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tail := b.tail
+	if len(tail) > b.tailLimit() {
+		tail = tail[len(tail)-b.tailLimit():]
+	}
+
+	dropped := b.total - int64(len(b.head)) - int64(len(tail))
+	if dropped == 0 {
+		return string(b.head) + string(tail)
+	}
+	return fmt.Sprintf("%s\n... [%d bytes truncated] ...\n%s", b.head, dropped, tail)
+}
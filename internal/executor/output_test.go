@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeadTailBuffer(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		writes    []string
+		want      string
+		truncated bool
+	}{
+		{"fits", 10, []string{"abc", "def"}, "abcdef", false},
+		{"exactly full", 6, []string{"abc", "def"}, "abcdef", false},
+		{"keeps head and tail", 6, []string{"abcdefgh", "ijkl"}, "abc\n... [6 bytes truncated] ...\njkl", true},
+		{"many small writes", 4, strings.Split("0123456789", ""), "01\n... [6 bytes truncated] ...\n89", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := newHeadTailBuffer(tt.limit)
+			for _, w := range tt.writes {
+				if n, err := buf.Write([]byte(w)); n != len(w) || err != nil {
+					t.Fatalf("Write() = %d, %v", n, err)
+				}
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if got := buf.Truncated(); got != tt.truncated {
+				t.Errorf("Truncated() = %v, want %v", got, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestHeadTailBuffer_BoundedMemory(t *testing.T) {
+	buf := newHeadTailBuffer(100)
+	chunk := []byte(strings.Repeat("x", 7))
+	for range 10000 {
+		buf.Write(chunk)
+	}
+
+	if len(buf.head)+len(buf.tail) > 3*100 {
+		t.Errorf("Expected the buffer to stay bounded, holds %d bytes", len(buf.head)+len(buf.tail))
+	}
+	if !strings.Contains(buf.String(), "[69900 bytes truncated]") {
+		t.Errorf("Expected the dropped byte count in the output, got %q", buf.String())
+	}
+}
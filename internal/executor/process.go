@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultKillGrace is how long a command may take to exit after SIGTERM
+// before it is killed, unless its job sets kill_grace
+const defaultKillGrace = 10 * time.Second
+
+// maxKillGrace bounds kill_grace to half the default run heartbeat timeout,
+// so a worker that dies while a command shuts down has its run reaped soon
+// after the command would have been killed
+const maxKillGrace = 30 * time.Second
+
+// pipeCloseDelay is how long a stopped command's output pipes stay open
+// after its process group has been killed, for processes that left the group
+const pipeCloseDelay = 5 * time.Second
+
+// groupStopper stops a command's process group: SIGTERM first, then SIGKILL
+// once the kill grace has passed. It records the signals it sends.
+type groupStopper struct {
+	cmd   *exec.Cmd
+	grace time.Duration
+
+	mu    sync.Mutex
+	sent  syscall.Signal
+	timer *time.Timer
+}
+
+// newGroupStopper sets cmd up to run in its own process group and to be
+// stopped group-wide when its context ends
+func newGroupStopper(cmd *exec.Cmd, grace time.Duration) *groupStopper {
+	s := &groupStopper{cmd: cmd, grace: grace}
+
+	setProcessGroup(cmd)
+	cmd.Cancel = s.terminate
+	cmd.WaitDelay = grace + pipeCloseDelay
+	return s
+}
+
+// terminate sends SIGTERM to the group and schedules SIGKILL
+func (s *groupStopper) terminate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.timer = time.AfterFunc(s.grace, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.signal(syscall.SIGKILL)
+	})
+	return s.signal(syscall.SIGTERM)
+}
+
+// signal sends sig to the group, with s.mu held
+func (s *groupStopper) signal(sig syscall.Signal) error {
+	s.sent = sig
+	return signalGroup(s.cmd, sig)
+}
+
+// finish is called once the command has exited. It kills whatever is left
+// of a stopped group and returns the name of the signal that ended the
+// command, or "" if it exited by itself.
+func (s *groupStopper) finish() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	if s.sent != 0 {
+		signalGroup(s.cmd, syscall.SIGKILL)
+	}
+
+	// A process killed by a signal names it; one that exited after being
+	// asked to stop was ended by the last signal sent
+	if sig := exitSignal(s.cmd.ProcessState); sig != "" {
+		return sig
+	}
+	if s.sent != 0 {
+		return signalName(s.sent)
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+//go:build !unix
+
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup does nothing on platforms without process groups
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalGroup signals cmd's process alone on platforms without process
+// groups. Only killing is supported.
+func signalGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	if sig == syscall.SIGKILL {
+		return cmd.Process.Kill()
+	}
+	return cmd.Process.Signal(sig)
+}
+
+// exitSignal can't tell how a process ended on platforms without wait
+// statuses
+func exitSignal(state *os.ProcessState) string {
+	return ""
+}
+
+// signalName returns the name of the signals sent to stop commands
+func signalName(sig syscall.Signal) string {
+	switch sig {
+	case syscall.SIGTERM:
+		return "SIGTERM"
+	case syscall.SIGKILL:
+		return "SIGKILL"
+	default:
+		return fmt.Sprintf("signal %d", int(sig))
+	}
+}
@@ -0,0 +1,149 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// Factory creates the executor for a job type
+type Factory func(logger *zap.Logger, settings Settings) Executor
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register makes an executor available for jobs of the given type. It is
+// meant to be called from init and panics if the type is empty or already
+// registered, so mistakes show up at startup.
+func Register(jobType string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if jobType == "" {
+		panic("executor: Register with empty job type")
+	}
+	if factory == nil {
+		panic("executor: Register factory is nil for job type " + jobType)
+	}
+	if _, exists := factories[jobType]; exists {
+		panic("executor: Register called twice for job type " + jobType)
+	}
+
+	factories[jobType] = factory
+}
+
+// Types returns the registered job types in sorted order
+func Types() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	jobTypes := make([]string, 0, len(factories))
+	for jobType := range factories {
+		jobTypes = append(jobTypes, jobType)
+	}
+	slices.Sort(jobTypes)
+	return jobTypes
+}
+
+// JobType returns the type a job runs as, defaulting to command
+func JobType(job *types.Job) string {
+	if job.Type == "" {
+		return TypeCommand
+	}
+	return job.Type
+}
+
+// Registry dispatches each run to the executor registered for its job's
+// type. Executors are created on first use and reused afterwards.
+type Registry struct {
+	logger   *zap.Logger
+	settings Settings
+
+	mu        sync.Mutex
+	executors map[string]Executor
+}
+
+// NewRegistry creates a registry over the registered executor types
+func NewRegistry(logger *zap.Logger, settings Settings) *Registry {
+	return &Registry{
+		logger:    logger,
+		settings:  settings,
+		executors: make(map[string]Executor),
+	}
+}
+
+// get returns the executor for a job type, creating it if needed
+func (r *Registry) get(jobType string) (Executor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if executor, ok := r.executors[jobType]; ok {
+		return executor, nil
+	}
+
+	factoriesMu.RLock()
+	factory, ok := factories[jobType]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown job type '%s'", jobType)
+	}
+
+	executor := factory(r.logger.With(zap.String("job_type", jobType)), r.settings)
+	r.executors[jobType] = executor
+	return executor, nil
+}
+
+// Validate checks that a job's type is registered and, if its executor
+// supports it, that the job is configured correctly for that type
+func (r *Registry) Validate(job *types.Job) error {
+	executor, err := r.get(JobType(job))
+	if err != nil {
+		return err
+	}
+
+	if validator, ok := executor.(Validator); ok {
+		return validator.Validate(job)
+	}
+	return nil
+}
+
+// ValidateOverrides checks a run's overrides by validating the job as it
+// would run with them. Only command jobs take args and env.
+func (r *Registry) ValidateOverrides(job *types.Job, overrides *types.RunOverrides) error {
+	if overrides == nil {
+		return nil
+	}
+
+	if (overrides.Args != nil || len(overrides.Env) > 0) && JobType(job) != TypeCommand {
+		return fmt.Errorf("args and env can't be overridden for %s jobs", JobType(job))
+	}
+	if overrides.Timeout != nil && *overrides.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	return r.Validate(ApplyOverrides(job, overrides))
+}
+
+// Execute runs a job with the executor for its type
+func (r *Registry) Execute(ctx context.Context, job *types.Job, run *types.Run) *ExecutionResult {
+	executor, err := r.get(JobType(job))
+	if err != nil {
+		now := time.Now()
+		return &ExecutionResult{
+			Status:    types.RunStatusFailed,
+			Error:     err,
+			StartTime: now,
+			EndTime:   now,
+		}
+	}
+
+	return executor.Execute(ctx, job, run)
+}
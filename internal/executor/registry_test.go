@@ -0,0 +1,186 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+	"github.com/google/uuid"
+)
+
+// echoExecutor reports the run it was given as its output
+type echoExecutor struct{}
+
+func (echoExecutor) Execute(ctx context.Context, job *types.Job, run *types.Run) *ExecutionResult {
+	return &ExecutionResult{Status: types.RunStatusSucceeded, Output: run.ID.String()}
+}
+
+func (echoExecutor) Validate(job *types.Job) error {
+	if string(job.Config) != `{"ok":true}` {
+		return fmt.Errorf("config must be {\"ok\":true}")
+	}
+	return nil
+}
+
+func init() {
+	Register("test_echo", func(logger *zap.Logger, settings Settings) Executor { return echoExecutor{} })
+}
+
+func TestRegistry_Execute(t *testing.T) {
+	registry := NewRegistry(zaptest.NewLogger(t), Settings{})
+	ctx := context.Background()
+
+	// Jobs dispatch to the executor registered for their type
+	job := &types.Job{ID: uuid.New(), Name: "test_registry", Type: "test_echo"}
+	run := newTestRun(job)
+	result := registry.Execute(ctx, job, run)
+	if result.Status != types.RunStatusSucceeded || result.Output != run.ID.String() {
+		t.Errorf("Expected the test executor to run, got %s %q", result.Status, result.Output)
+	}
+
+	// Jobs without a type run as commands
+	job = &types.Job{ID: uuid.New(), Name: "test_registry_default", Command: "echo", Args: []string{"default"}}
+	result = registry.Execute(ctx, job, newTestRun(job))
+	if result.Status != types.RunStatusSucceeded || result.Output != "default\n" {
+		t.Errorf("Expected the command executor to run, got %s %q", result.Status, result.Output)
+	}
+
+	// Unknown types fail the run instead of panicking
+	job = &types.Job{ID: uuid.New(), Name: "test_registry_unknown", Type: "nonexistent"}
+	result = registry.Execute(ctx, job, newTestRun(job))
+	if result.Status != types.RunStatusFailed || result.Error == nil {
+		t.Errorf("Expected unknown type to fail, got %s %v", result.Status, result.Error)
+	}
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	registry := NewRegistry(zaptest.NewLogger(t), Settings{})
+
+	tests := []struct {
+		name    string
+		job     *types.Job
+		wantErr bool
+	}{
+		{"command", &types.Job{Command: "echo"}, false},
+		{"command without command", &types.Job{Type: TypeCommand}, true},
+		{"custom type", &types.Job{Type: "test_echo", Config: []byte(`{"ok":true}`)}, false},
+		{"custom type with bad config", &types.Job{Type: "test_echo"}, true},
+		{"unknown type", &types.Job{Type: "nonexistent"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Validate(tt.job)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	if !slices.Contains(Types(), TypeCommand) || !slices.Contains(Types(), "test_echo") {
+		t.Errorf("Expected command and test_echo to be registered, got %v", Types())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a type twice to panic")
+		}
+	}()
+	Register(TypeCommand, func(logger *zap.Logger, settings Settings) Executor { return echoExecutor{} })
+}
+
+func TestApplyOverrides(t *testing.T) {
+	minute := types.Duration(time.Minute)
+	job := &types.Job{
+		Command: "echo",
+		Args:    []string{"hello"},
+		Env:     map[string]string{"A": "1", "B": "2"},
+		Timeout: &minute,
+	}
+
+	if got := ApplyOverrides(job, nil); got != job {
+		t.Errorf("Expected the job itself without overrides, got %+v", got)
+	}
+
+	timeout := types.Duration(5 * time.Second)
+	got := ApplyOverrides(job, &types.RunOverrides{
+		Args:    []string{"bye"},
+		Env:     map[string]string{"B": "3", "C": "4"},
+		Timeout: &timeout,
+	})
+
+	if len(got.Args) != 1 || got.Args[0] != "bye" {
+		t.Errorf("Expected args [bye], got %v", got.Args)
+	}
+	if got.Env["A"] != "1" || got.Env["B"] != "3" || got.Env["C"] != "4" {
+		t.Errorf("Expected env merged over the job's, got %v", got.Env)
+	}
+	if got.Timeout == nil || *got.Timeout != timeout {
+		t.Errorf("Expected timeout %v, got %v", timeout, got.Timeout)
+	}
+
+	// The stored job must not change
+	if job.Args[0] != "hello" || job.Env["B"] != "2" || len(job.Env) != 2 || *job.Timeout != minute {
+		t.Errorf("Expected job to be left untouched, got %+v", job)
+	}
+
+	// Only the overridden fields change
+	got = ApplyOverrides(job, &types.RunOverrides{Env: map[string]string{"C": "4"}})
+	if got.Args[0] != "hello" || *got.Timeout != minute {
+		t.Errorf("Expected args and timeout from the job, got %v %v", got.Args, *got.Timeout)
+	}
+}
+
+func TestRegistry_ValidateOverrides(t *testing.T) {
+	registry := NewRegistry(zaptest.NewLogger(t), Settings{})
+
+	command := &types.Job{Command: "echo", Config: json.RawMessage(`{"env_mode":"inherit"}`)}
+	httpJob := &types.Job{Type: TypeHTTP, Config: json.RawMessage(`{"url":"https://example.com"}`)}
+	timeout := types.Duration(time.Minute)
+	zero := types.Duration(0)
+
+	tests := []struct {
+		name      string
+		job       *types.Job
+		overrides *types.RunOverrides
+		wantErr   string
+	}{
+		{"none", command, nil, ""},
+		{"args", command, &types.RunOverrides{Args: []string{"--dry-run"}}, ""},
+		{"timeout for http", httpJob, &types.RunOverrides{Timeout: &timeout}, ""},
+		{"zero timeout", command, &types.RunOverrides{Timeout: &zero}, "timeout must be positive"},
+		{"args for http", httpJob, &types.RunOverrides{Args: []string{"x"}}, "can't be overridden for http jobs"},
+		{"env for inherit", command, &types.RunOverrides{Env: map[string]string{"A": "1"}}, "env_mode 'inherit'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.ValidateOverrides(tt.job, tt.overrides)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// Reserved names are rejected for merge jobs too
+	merge := &types.Job{Command: "echo"}
+	if err := registry.ValidateOverrides(merge, &types.RunOverrides{Env: map[string]string{"ASTER_RUN_ID": "x"}}); err == nil {
+		t.Error("Expected a reserved env override to be rejected")
+	}
+}
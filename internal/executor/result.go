@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// resultFileName is the file ASTER_RESULT_PATH points to, in a directory
+	// created for each run
+	resultFileName = "result.json"
+
+	// resultLinePrefix marks a final stdout line carrying the run's result
+	resultLinePrefix = "::aster-result::"
+
+	// maxResultBytes bounds the size of a run's result
+	maxResultBytes = 64 << 10
+)
+
+// newResultDir creates a private directory for a command to write its
+// result file in, owned by the account the command runs as
+func newResultDir(account *runAsAccount) (string, error) {
+	dir, err := os.MkdirTemp("", "aster-run-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create result directory: %w", err)
+	}
+
+	if account != nil {
+		uid, gid := account.owner()
+		if err := os.Chown(dir, uid, gid); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("failed to create result directory: %w", err)
+		}
+	}
+
+	return dir, nil
+}
+
+// readResult returns the result a command reported: the JSON object in its
+// result file, or else the one on its final stdout line after
+// resultLinePrefix. It returns nil if the command reported none.
+func readResult(path, stdout string) (json.RawMessage, error) {
+	data, err := readResultFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		lines := strings.TrimRight(stdout, "\r\n")
+		last := lines[strings.LastIndexByte(lines, '\n')+1:]
+		if !strings.HasPrefix(last, resultLinePrefix) {
+			return nil, nil
+		}
+		data = []byte(strings.TrimPrefix(last, resultLinePrefix))
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) > maxResultBytes {
+		return nil, fmt.Errorf("invalid result: larger than %d bytes", maxResultBytes)
+	}
+	if !json.Valid(data) || data[0] != '{' {
+		return nil, fmt.Errorf("invalid result: must be a JSON object")
+	}
+
+	return json.RawMessage(data), nil
+}
+
+// readResultFile reads a result file, returning nil if the command didn't
+// write one. Only regular files are read, so a command can't point the
+// worker at files it may not read itself.
+func readResultFile(path string) ([]byte, error) {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result file: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("invalid result: %s must be a regular file", resultFileName)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result file: %w", err)
+	}
+	defer f.Close()
+
+	// Make sure the file opened is the one checked above
+	opened, err := f.Stat()
+	if err != nil || !os.SameFile(info, opened) {
+		return nil, fmt.Errorf("invalid result: %s changed while being read", resultFileName)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(f, maxResultBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result file: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// resultPath returns the path of the result file in a run's result directory
+func resultPath(dir string) string {
+	return filepath.Join(dir, resultFileName)
+}
@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		stdout  string
+		want    string
+		wantErr bool
+	}{
+		{"none", "", "done\n", "", false},
+		{"file", `{"rows": 1234}` + "\n", "done\n", `{"rows": 1234}`, false},
+		{"file wins over stdout", `{"from": "file"}`, `::aster-result::{"from": "stdout"}`, `{"from": "file"}`, false},
+		{"final stdout line", "", "working\n::aster-result::{\"rows\": 5}\n", `{"rows": 5}`, false},
+		{"marker not on final line", "", "::aster-result::{\"rows\": 5}\ndone\n", "", false},
+		{"invalid json", "{rows: 5}", "", "", true},
+		{"not an object", "[1, 2]", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), resultFileName)
+			if tt.file != "" {
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := readResult(path, tt.stdout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("readResult() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadResult_RejectsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "secret.json")
+	if err := os.WriteFile(target, []byte(`{"secret": true}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, resultFileName)
+	if err := os.Symlink(target, path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readResult(path, ""); err == nil {
+		t.Error("Expected a symlinked result file to be rejected")
+	}
+}
@@ -0,0 +1,283 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// TypeSQL runs a statement or script against a Postgres database
+const TypeSQL = "sql"
+
+// defaultSQLMaxRows is how many result rows are kept as output by default
+const defaultSQLMaxRows = 100
+
+// sqlConnectionEnvPrefix prefixes the worker environment variables that hold
+// the DSNs of named connections
+const sqlConnectionEnvPrefix = "SQL_CONNECTION_"
+
+// connectionNamePattern matches names usable in an environment variable
+var connectionNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+func init() {
+	Register(TypeSQL, func(logger *zap.Logger, _ Settings) Executor {
+		return NewSQLExecutor(logger)
+	})
+}
+
+// SQLConfig is the config of a sql job. Exactly one of DSN and Connection
+// must be set.
+type SQLConfig struct {
+	DSN        string `json:"dsn,omitempty"`        // Connection string stored with the job
+	Connection string `json:"connection,omitempty"` // Named connection defined on the worker
+	Statement  string `json:"statement"`            // Statement, or script of several
+	MaxRows    int    `json:"max_rows,omitempty"`   // Result rows kept per statement
+}
+
+// SQLExecutor handles running sql jobs
+type SQLExecutor struct {
+	logger *zap.Logger
+}
+
+// NewSQLExecutor creates a new sql executor
+func NewSQLExecutor(logger *zap.Logger) *SQLExecutor {
+	return &SQLExecutor{
+		logger: logger,
+	}
+}
+
+// parseSQLConfig reads and checks a sql job's config
+func parseSQLConfig(job *types.Job) (*SQLConfig, error) {
+	var cfg SQLConfig
+	if err := decodeConfig(job, &cfg); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(cfg.Statement) == "" {
+		return nil, fmt.Errorf("config.statement is required")
+	}
+
+	switch {
+	case cfg.DSN == "" && cfg.Connection == "":
+		return nil, fmt.Errorf("config.dsn or config.connection is required")
+	case cfg.DSN != "" && cfg.Connection != "":
+		return nil, fmt.Errorf("config.dsn and config.connection are mutually exclusive")
+	case cfg.DSN != "":
+		if _, err := pgx.ParseConfig(cfg.DSN); err != nil {
+			return nil, fmt.Errorf("invalid config.dsn: %w", err)
+		}
+	case !connectionNamePattern.MatchString(cfg.Connection):
+		return nil, fmt.Errorf("config.connection must be letters, digits and underscores")
+	}
+
+	if cfg.MaxRows < 0 {
+		return nil, fmt.Errorf("config.max_rows must not be negative")
+	}
+	if cfg.MaxRows == 0 {
+		cfg.MaxRows = defaultSQLMaxRows
+	}
+
+	return &cfg, nil
+}
+
+// dsn returns the connection string to use, resolving named connections from
+// the worker's environment
+func (c *SQLConfig) dsn() (string, error) {
+	if c.DSN != "" {
+		return c.DSN, nil
+	}
+
+	key := sqlConnectionEnvPrefix + strings.ToUpper(c.Connection)
+	dsn, ok := os.LookupEnv(key)
+	if !ok || dsn == "" {
+		return "", fmt.Errorf("connection '%s' is not configured on this worker (%s is not set)", c.Connection, key)
+	}
+	return dsn, nil
+}
+
+// Validate checks a sql job's config
+func (e *SQLExecutor) Validate(job *types.Job) error {
+	_, err := parseSQLConfig(job)
+	return err
+}
+
+// Execute runs the job's statements and records their results
+func (e *SQLExecutor) Execute(ctx context.Context, job *types.Job, run *types.Run) *ExecutionResult {
+	result := &ExecutionResult{
+		StartTime: time.Now(),
+		Status:    types.RunStatusRunning,
+	}
+
+	finish := func(status types.RunStatus, err error) *ExecutionResult {
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(result.StartTime)
+		result.Status = status
+		result.Error = err
+
+		e.logger.Info("Job execution completed",
+			zap.String("job_id", job.ID.String()),
+			zap.String("job_name", job.Name),
+			zap.String("status", string(result.Status)),
+			zap.Duration("duration", result.Duration))
+		return result
+	}
+
+	cfg, err := parseSQLConfig(job)
+	if err != nil {
+		return finish(types.RunStatusFailed, err)
+	}
+
+	dsn, err := cfg.dsn()
+	if err != nil {
+		return finish(types.RunStatusFailed, err)
+	}
+
+	e.logger.Info("Starting job execution",
+		zap.String("job_id", job.ID.String()),
+		zap.String("job_name", job.Name),
+		zap.String("connection", cfg.Connection))
+
+	// The timeout covers connecting as well; cancelling the context makes pgx
+	// cancel the running query on the server
+	queryCtx := ctx
+	if job.Timeout != nil {
+		var cancel context.CancelFunc
+		queryCtx, cancel = context.WithTimeout(ctx, time.Duration(*job.Timeout))
+		defer cancel()
+	}
+
+	// checkStopped reports statements that ended because they ran out of
+	// time or the run was cancelled
+	checkStopped := func() *ExecutionResult {
+		if ctx.Err() != nil {
+			return finish(types.RunStatusCancelled, fmt.Errorf("job was cancelled"))
+		}
+		if errors.Is(queryCtx.Err(), context.DeadlineExceeded) {
+			return finish(types.RunStatusTimedOut, fmt.Errorf("job timed out after %s", job.Timeout))
+		}
+		return nil
+	}
+
+	conn, err := pgx.Connect(queryCtx, dsn)
+	if err != nil {
+		if stopped := checkStopped(); stopped != nil {
+			return stopped
+		}
+		return finish(types.RunStatusFailed, fmt.Errorf("failed to connect: %w", err))
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	// The simple protocol runs scripts of several statements, and statements
+	// such as VACUUM that can't run in a transaction
+	var output strings.Builder
+	results := conn.PgConn().Exec(queryCtx, cfg.Statement)
+	for results.NextResult() {
+		if err := writeStatementResult(&output, results.ResultReader(), cfg.MaxRows); err != nil {
+			results.Close()
+			result.Output = output.String()
+			if stopped := checkStopped(); stopped != nil {
+				return stopped
+			}
+			return finish(types.RunStatusFailed, fmt.Errorf("statement failed: %w", err))
+		}
+	}
+	err = results.Close()
+	result.Output = output.String()
+	if err != nil {
+		if stopped := checkStopped(); stopped != nil {
+			return stopped
+		}
+		return finish(types.RunStatusFailed, fmt.Errorf("statement failed: %w", err))
+	}
+
+	return finish(types.RunStatusSucceeded, nil)
+}
+
+// writeStatementResult writes one statement's result to output: a table of
+// at most maxRows rows for queries, then the command tag, which includes the
+// number of rows affected
+func writeStatementResult(output *strings.Builder, reader *pgconn.ResultReader, maxRows int) error {
+	var columns []string
+	for _, field := range reader.FieldDescriptions() {
+		columns = append(columns, field.Name)
+	}
+
+	var rows [][]string
+	total := 0
+	for reader.NextRow() {
+		total++
+		if len(rows) == maxRows {
+			continue
+		}
+
+		values := reader.Values()
+		row := make([]string, len(values))
+		for i, value := range values {
+			if value == nil {
+				row[i] = "NULL"
+			} else {
+				row[i] = string(value)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	tag, err := reader.Close()
+	if err != nil {
+		return err
+	}
+
+	if len(columns) > 0 {
+		writeTable(output, columns, rows)
+		if total > len(rows) {
+			fmt.Fprintf(output, "... (%d more rows)\n", total-len(rows))
+		}
+	}
+	fmt.Fprintf(output, "%s\n", tag.String())
+	return nil
+}
+
+// writeTable writes rows under a header, padding each column to its widest
+// value
+func writeTable(output *strings.Builder, columns []string, rows [][]string) {
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = len(column)
+	}
+	for _, row := range rows {
+		for i, value := range row {
+			widths[i] = max(widths[i], len(value))
+		}
+	}
+
+	writeRow := func(values []string) {
+		var line strings.Builder
+		for i, value := range values {
+			if i > 0 {
+				line.WriteString(" | ")
+			}
+			fmt.Fprintf(&line, "%-*s", widths[i], value)
+		}
+		output.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+
+	writeRow(columns)
+	separators := make([]string, len(columns))
+	for i, width := range widths {
+		separators[i] = strings.Repeat("-", width)
+	}
+	output.WriteString(strings.Join(separators, "-+-") + "\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+}
@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// templateFuncs are the helpers available to job templates, written to be
+// piped into, as in {{.ScheduledAt | add "-24h" | date "2006-01-02"}}
+var templateFuncs = template.FuncMap{
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	"add": func(duration string, t time.Time) (time.Time, error) {
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return t.Add(d), nil
+	},
+	"addDays": func(days int, t time.Time) time.Time {
+		return t.AddDate(0, 0, days)
+	},
+	"utc": func(t time.Time) time.Time {
+		return t.UTC()
+	},
+	"in": func(zone string, t time.Time) (time.Time, error) {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return t.In(loc), nil
+	},
+}
+
+// templateData is what job templates can refer to when a run executes
+type templateData struct {
+	Job         *types.Job
+	Run         *types.Run
+	ScheduledAt time.Time
+	Attempt     int
+}
+
+// newTemplateData returns the template context for a run of job. The run's
+// slot is given in the job's time zone, or UTC if it has none, whatever zone
+// it was read from the database in.
+func newTemplateData(job *types.Job, run *types.Run) templateData {
+	loc, err := time.LoadLocation(job.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	return templateData{
+		Job:         job,
+		Run:         run,
+		ScheduledAt: run.ScheduledAt.In(loc),
+		Attempt:     run.AttemptNum,
+	}
+}
+
+// parseTemplate parses a job template. Unknown map keys are errors so typos
+// fail the run instead of rendering as "<no value>".
+func parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// renderTemplate parses and executes a job template against data
+func renderTemplate(name, text string, data templateData) (string, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return sb.String(), nil
+}
+
+// renderCommandTemplates returns a copy of a command job with the templates
+// in its command, args and env values rendered for run
+func renderCommandTemplates(job *types.Job, run *types.Run) (*types.Job, error) {
+	data := newTemplateData(job, run)
+	rendered := *job
+
+	var err error
+	rendered.Command, err = renderTemplate("command", job.Command, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Args != nil {
+		rendered.Args = make([]string, len(job.Args))
+		for i, arg := range job.Args {
+			rendered.Args[i], err = renderTemplate(fmt.Sprintf("args[%d]", i), arg, data)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if job.Env != nil {
+		rendered.Env = make(map[string]string, len(job.Env))
+		for key, value := range job.Env {
+			rendered.Env[key], err = renderTemplate("env."+key, value, data)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &rendered, nil
+}
+
+// validateCommandTemplates checks a command job's templates by rendering
+// them for a sample run, so misspelt fields are caught along with syntax
+// errors
+func validateCommandTemplates(job *types.Job) error {
+	run := &types.Run{
+		JobID:       job.ID,
+		Status:      types.RunStatusRunning,
+		AttemptNum:  1,
+		Trigger:     types.RunTriggerSchedule,
+		ScheduledAt: time.Now().UTC(),
+	}
+	_, err := renderCommandTemplates(job, run)
+	return err
+}
@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+	"github.com/google/uuid"
+)
+
+func TestRenderCommandTemplates(t *testing.T) {
+	job := &types.Job{
+		ID:       uuid.New(),
+		Name:     "daily_export",
+		Timezone: "Europe/Berlin",
+		Command:  "export-{{.Job.Name}}",
+		Args: []string{
+			`--date={{.ScheduledAt | date "2006-01-02"}}`,
+			`--since={{.ScheduledAt | add "-36h" | date "2006-01-02T15"}}`,
+			`--day={{.ScheduledAt | in .Job.Timezone | addDays 1 | date "Jan 2"}}`,
+			"--attempt={{.Attempt}}",
+		},
+		Env: map[string]string{"RUN": "{{.Run.ID}}", "PLAIN": "value"},
+	}
+	run := newTestRun(job)
+	run.AttemptNum = 3
+	run.ScheduledAt = time.Date(2024, 3, 1, 23, 30, 0, 0, time.UTC)
+
+	rendered, err := renderCommandTemplates(job, run)
+	if err != nil {
+		t.Fatalf("Expected templates to render, got: %v", err)
+	}
+
+	if rendered.Command != "export-daily_export" {
+		t.Errorf("Expected rendered command, got %q", rendered.Command)
+	}
+
+	// .ScheduledAt is in the job's time zone, an hour ahead of UTC
+	want := []string{"--date=2024-03-02", "--since=2024-02-29T12", "--day=Mar 3", "--attempt=3"}
+	for i, arg := range want {
+		if rendered.Args[i] != arg {
+			t.Errorf("Expected arg %d to be %q, got %q", i, arg, rendered.Args[i])
+		}
+	}
+
+	if rendered.Env["RUN"] != run.ID.String() || rendered.Env["PLAIN"] != "value" {
+		t.Errorf("Expected rendered env, got %v", rendered.Env)
+	}
+
+	// The job itself is left alone
+	if job.Args[3] != "--attempt={{.Attempt}}" {
+		t.Errorf("Expected the job's args to be unchanged, got %q", job.Args[3])
+	}
+}
+
+func TestNewTemplateData_Timezone(t *testing.T) {
+	// Runs read from the database carry the worker's local zone
+	scheduledAt := time.Date(2024, 7, 1, 22, 0, 0, 0, time.UTC).In(time.FixedZone("worker", -5*3600))
+
+	tests := []struct {
+		timezone string
+		want     string
+	}{
+		{"America/New_York", "2024-07-01T18:00:00-04:00"},
+		{"Asia/Tokyo", "2024-07-02T07:00:00+09:00"},
+		{"", "2024-07-01T22:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			job := &types.Job{ID: uuid.New(), Name: "tz", Timezone: tt.timezone}
+			run := newTestRun(job)
+			run.ScheduledAt = scheduledAt
+
+			got, err := renderTemplate("arg", "{{.ScheduledAt | date \"2006-01-02T15:04:05Z07:00\"}}", newTemplateData(job, run))
+			if err != nil {
+				t.Fatalf("Expected template to render, got: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateCommandTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     *types.Job
+		wantErr string
+	}{
+		{"valid", &types.Job{Command: "echo", Args: []string{"{{.ScheduledAt | date \"2006\"}}"}}, ""},
+		{"syntax error", &types.Job{Command: "echo", Args: []string{"{{.Attempt"}}, "invalid args[0] template"},
+		{"unknown field", &types.Job{Command: "echo {{.Job.Nmae}}"}, "can't evaluate field Nmae"},
+		{"unknown function", &types.Job{Command: "echo", Env: map[string]string{"D": "{{now}}"}}, "invalid env.D template"},
+		{"bad duration", &types.Job{Command: "echo", Args: []string{`{{.ScheduledAt | add "1 day"}}`}}, "failed to render args[0] template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCommandTemplates(tt.job)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
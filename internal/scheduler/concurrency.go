@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/db/store"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// ApplyConcurrencyDefaults fills in a job's concurrency policy if unset
+func ApplyConcurrencyDefaults(job *types.Job) {
+	if job.ConcurrencyPolicy == "" {
+		job.ConcurrencyPolicy = types.ConcurrencyAllow
+	}
+}
+
+// ValidateConcurrency checks a job's concurrency policy and run limit
+func ValidateConcurrency(job *types.Job) error {
+	switch job.ConcurrencyPolicy {
+	case "", types.ConcurrencyAllow, types.ConcurrencyForbid, types.ConcurrencyReplace:
+	default:
+		return fmt.Errorf("unknown concurrency policy '%s'", job.ConcurrencyPolicy)
+	}
+
+	if job.MaxConcurrentRuns < 0 {
+		return fmt.Errorf("max_concurrent_runs must not be negative")
+	}
+
+	return nil
+}
+
+// applyConcurrencyPolicy handles runs of job that are still active when new
+// slots come due. It reports whether the new slots should be skipped.
+func (s *Scheduler) applyConcurrencyPolicy(ctx context.Context, runStore *store.RunStore, job *types.Job) (bool, error) {
+	switch job.ConcurrencyPolicy {
+	case types.ConcurrencyForbid:
+		active, err := runStore.CountActiveRuns(ctx, job.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to check active runs for job %s: %w", job.Name, err)
+		}
+		return active > 0, nil
+
+	case types.ConcurrencyReplace:
+		// Workers stop flagged runs; claims hold the new run back until they have
+		cancelled, err := runStore.RequestCancelActiveRuns(ctx, job.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to replace active runs for job %s: %w", job.Name, err)
+		}
+		if cancelled > 0 {
+			s.logger.Info("Requested cancellation of runs replaced by new slot",
+				zap.String("job_id", job.ID.String()),
+				zap.String("job_name", job.Name),
+				zap.Int("count", cancelled))
+		}
+		return false, nil
+
+	default:
+		return false, nil
+	}
+}
@@ -7,6 +7,12 @@ import (
 	"github.com/robfig/cron/v3"
 )
 
+// starBit marks a cron field written as a bare wildcard (mirrors robfig/cron)
+const starBit = 1 << 63
+
+// maxWallIterations bounds the search for a slot that survives DST adjustment
+const maxWallIterations = 1000
+
 // CronParser handles parsing cron expressions and calculating next run times
 type CronParser struct {
 	parser cron.Parser
@@ -30,8 +36,35 @@ func NewCronParser() *CronParser {
 	}
 }
 
+// LoadLocation resolves a job's time zone from the tz database. An empty
+// name means UTC.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	// "Local" would silently depend on the host running the scheduler
+	if name == "Local" {
+		return nil, fmt.Errorf("time zone must be an IANA name such as 'Europe/Berlin', not 'Local'")
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone '%s': %w", name, err)
+	}
+
+	return loc, nil
+}
+
 // ParserAndNext parsers a cron expression and returns the next run time
+// evaluated in fromTime's location
 func (cp *CronParser) ParserAndNext(cronExpr string, fromTime time.Time) (time.Time, error) {
+	return cp.NextInLocation(cronExpr, fromTime, fromTime.Location())
+}
+
+// NextInLocation parses a cron expression and returns the next run time after
+// fromTime, evaluating the expression against the wall clock in loc
+func (cp *CronParser) NextInLocation(cronExpr string, fromTime time.Time, loc *time.Location) (time.Time, error) {
 	// Parse the cron expression
 	schedule, err := cp.parser.Parse(cronExpr)
 
@@ -40,7 +73,7 @@ func (cp *CronParser) ParserAndNext(cronExpr string, fromTime time.Time) (time.T
 	}
 
 	// Calculate next run time from the given time
-	nextRun := schedule.Next(fromTime)
+	nextRun := nextInLocation(schedule, fromTime, loc)
 
 	// Check if we got a valid next run
 	if nextRun.IsZero() {
@@ -60,7 +93,14 @@ func (cp *CronParser) Validate(cronExpr string) error {
 }
 
 // GetNextRuns returns the next N run times for a cron expression
+// evaluated in fromTime's location
 func (cp *CronParser) GetNextNRuns(cronExpr string, fromTime time.Time, n int) ([]time.Time, error) {
+	return cp.GetNextNRunsInLocation(cronExpr, fromTime, fromTime.Location(), n)
+}
+
+// GetNextNRunsInLocation returns the next N run times for a cron expression
+// evaluated against the wall clock in loc
+func (cp *CronParser) GetNextNRunsInLocation(cronExpr string, fromTime time.Time, loc *time.Location, n int) ([]time.Time, error) {
 	schedule, err := cp.parser.Parse(cronExpr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid cron expression '%s': %w", cronExpr, err)
@@ -70,7 +110,7 @@ func (cp *CronParser) GetNextNRuns(cronExpr string, fromTime time.Time, n int) (
 	currentTime := fromTime
 
 	for range n {
-		nextRun := schedule.Next(currentTime)
+		nextRun := nextInLocation(schedule, currentTime, loc)
 		if nextRun.IsZero() {
 			break // No more runs
 		}
@@ -79,3 +119,101 @@ func (cp *CronParser) GetNextNRuns(cronExpr string, fromTime time.Time, n int) (
 	}
 	return nextRuns, nil
 }
+
+// GetRunsBetween returns every run time from start through end (both
+// inclusive) evaluated in loc. It fails if the range holds more than max runs.
+func (cp *CronParser) GetRunsBetween(cronExpr string, start, end time.Time, loc *time.Location, max int) ([]time.Time, error) {
+	schedule, err := cp.parser.Parse(cronExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': %w", cronExpr, err)
+	}
+
+	var runs []time.Time
+
+	// Step back so a slot exactly at start is included
+	nextRun := nextInLocation(schedule, start.Add(-time.Nanosecond), loc)
+	for !nextRun.IsZero() && !nextRun.After(end) {
+		if len(runs) == max {
+			return nil, fmt.Errorf("range contains more than %d runs", max)
+		}
+		runs = append(runs, nextRun)
+		nextRun = nextInLocation(schedule, nextRun, loc)
+	}
+	return runs, nil
+}
+
+// nextInLocation returns the first fire time of schedule after from in loc.
+//
+// Schedules with a fixed hour (such as "0 2 * * *") follow the wall clock the
+// way cron(8) does: each local time fires once even when a DST overlap repeats
+// it, and a slot skipped by a DST gap fires as soon as the clock jumps past
+// it. Schedules with a wildcard hour (such as "*/30 * * * *") and @every
+// intervals keep firing at their regular pace in absolute time instead.
+func nextInLocation(schedule cron.Schedule, from time.Time, loc *time.Location) time.Time {
+	spec, ok := schedule.(*cron.SpecSchedule)
+	if !ok || spec.Hour&starBit != 0 || spec.Location != time.Local {
+		// Absolute schedules, or an explicit CRON_TZ prefix that takes precedence
+		return schedule.Next(from.In(loc))
+	}
+
+	// Evaluate in a DST-free copy of the local wall clock, then map back
+	wall := wallClock(from.In(loc))
+	for range maxWallIterations {
+		wall = schedule.Next(wall)
+		if wall.IsZero() {
+			return time.Time{}
+		}
+
+		// Slots mapping to or before from were already fired (repeated wall
+		// times in an overlap, or several slots collapsed onto a gap's end)
+		if next := resolveWallClock(wall, loc); next.After(from) {
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+// wallClock reinterprets t's clock reading as UTC so it can be stepped
+// through without DST jumps
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// resolveWallClock returns the earliest instant whose clock reads wall in loc.
+// If DST skips that reading, it returns the instant the clock jumps past it.
+func resolveWallClock(wall time.Time, loc *time.Location) time.Time {
+	guess := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+	start, end := guess.ZoneBounds()
+
+	// Try the offsets of the zone Go picked and its neighbours; in an overlap
+	// two of them produce the same reading and we want the earlier one
+	var earliest time.Time
+	consider := func(offset int) {
+		t := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if wallClock(t).Equal(wall) && (earliest.IsZero() || t.Before(earliest)) {
+			earliest = t
+		}
+	}
+
+	_, offset := guess.Zone()
+	consider(offset)
+	if !start.IsZero() {
+		_, prev := start.Add(-time.Nanosecond).Zone()
+		consider(prev)
+	}
+	if !end.IsZero() {
+		_, next := end.Zone()
+		consider(next)
+	}
+
+	if !earliest.IsZero() {
+		return earliest
+	}
+
+	// The reading falls in a DST gap: fire when the new offset takes effect
+	if wallClock(guess).After(wall) {
+		return start
+	}
+	return end
+}
@@ -140,3 +140,207 @@ func TestCronParser_GetNextNRuns(t *testing.T) {
 		}
 	}
 }
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := LoadLocation(name)
+	if err != nil {
+		t.Fatalf("Failed to load location %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestLoadLocation(t *testing.T) {
+	if loc, err := LoadLocation(""); err != nil || loc != time.UTC {
+		t.Errorf("Expected empty time zone to mean UTC, got %v, %v", loc, err)
+	}
+
+	if _, err := LoadLocation("Europe/Berlin"); err != nil {
+		t.Errorf("Expected Europe/Berlin to be valid, got error: %v", err)
+	}
+
+	for _, name := range []string{"Local", "Mars/Olympus_Mons", "+03:00"} {
+		if _, err := LoadLocation(name); err == nil {
+			t.Errorf("Expected '%s' to be rejected", name)
+		}
+	}
+}
+
+func TestCronParser_NextInLocation(t *testing.T) {
+	parser := NewCronParser()
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name     string
+		cronExpr string
+		from     time.Time
+		loc      *time.Location
+		want     time.Time
+	}{
+		{
+			name:     "fires at local time regardless of from's zone",
+			cronExpr: "0 2 * * *",
+			from:     time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC),
+			loc:      berlin,
+			want:     time.Date(2024, 1, 16, 1, 0, 0, 0, time.UTC), // 02:00 CET
+		},
+		{
+			name:     "summer time offset",
+			cronExpr: "0 9 * * *",
+			from:     time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+			loc:      newYork,
+			want:     time.Date(2024, 7, 1, 13, 0, 0, 0, time.UTC), // 09:00 EDT
+		},
+		{
+			name:     "slot in DST gap fires when clocks jump",
+			cronExpr: "30 2 * * *",
+			from:     time.Date(2024, 3, 30, 12, 0, 0, 0, time.UTC),
+			loc:      berlin,
+			want:     time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC), // 03:00 CEST
+		},
+		{
+			name:     "slot in DST overlap fires on first pass",
+			cronExpr: "30 2 * * *",
+			from:     time.Date(2024, 10, 26, 12, 0, 0, 0, time.UTC),
+			loc:      berlin,
+			want:     time.Date(2024, 10, 27, 0, 30, 0, 0, time.UTC), // 02:30 CEST
+		},
+		{
+			name:     "slot in DST overlap does not fire on second pass",
+			cronExpr: "30 2 * * *",
+			from:     time.Date(2024, 10, 27, 0, 30, 0, 0, time.UTC),
+			loc:      berlin,
+			want:     time.Date(2024, 10, 28, 1, 30, 0, 0, time.UTC), // next day 02:30 CET
+		},
+		{
+			name:     "CRON_TZ prefix overrides job time zone",
+			cronExpr: "CRON_TZ=UTC 0 2 * * *",
+			from:     time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC),
+			loc:      berlin,
+			want:     time.Date(2024, 1, 16, 2, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.NextInLocation(tt.cronExpr, tt.from, tt.loc)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("NextInLocation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronParser_GetNextNRunsInLocation_DST(t *testing.T) {
+	parser := NewCronParser()
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name     string
+		cronExpr string
+		from     time.Time
+		loc      *time.Location
+		want     []time.Time
+	}{
+		{
+			name:     "daily job keeps local hour across spring forward",
+			cronExpr: "0 9 * * *",
+			from:     time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC),
+			loc:      newYork,
+			want: []time.Time{
+				time.Date(2024, 3, 9, 14, 0, 0, 0, time.UTC),  // 09:00 EST
+				time.Date(2024, 3, 10, 13, 0, 0, 0, time.UTC), // 09:00 EDT
+				time.Date(2024, 3, 11, 13, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "daily job in gap fires once per day",
+			cronExpr: "30 2 * * *",
+			from:     time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC),
+			loc:      berlin,
+			want: []time.Time{
+				time.Date(2024, 3, 30, 1, 30, 0, 0, time.UTC), // 02:30 CET
+				time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC),  // 03:00 CEST, end of gap
+				time.Date(2024, 4, 1, 0, 30, 0, 0, time.UTC),  // 02:30 CEST
+			},
+		},
+		{
+			name:     "slots collapsed onto gap end fire once",
+			cronExpr: "0,30 2 * * *",
+			from:     time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+			loc:      berlin,
+			want: []time.Time{
+				time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC), // 02:00 and 02:30 both map to 03:00 CEST
+				time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "interval job keeps pace through overlap",
+			cronExpr: "*/30 * * * *",
+			from:     time.Date(2024, 10, 27, 0, 0, 0, 0, time.UTC), // 02:00 CEST
+			loc:      berlin,
+			want: []time.Time{
+				time.Date(2024, 10, 27, 0, 30, 0, 0, time.UTC), // 02:30 CEST
+				time.Date(2024, 10, 27, 1, 0, 0, 0, time.UTC),  // 02:00 CET
+				time.Date(2024, 10, 27, 1, 30, 0, 0, time.UTC), // 02:30 CET
+				time.Date(2024, 10, 27, 2, 0, 0, 0, time.UTC),  // 03:00 CET
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, err := parser.GetNextNRunsInLocation(tt.cronExpr, tt.from, tt.loc, len(tt.want))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(runs) != len(tt.want) {
+				t.Fatalf("Expected %d runs, got %d", len(tt.want), len(runs))
+			}
+
+			for i, run := range runs {
+				if !run.Equal(tt.want[i]) {
+					t.Errorf("Run %d: expected %v, got %v", i, tt.want[i], run.UTC())
+				}
+			}
+		})
+	}
+}
+
+func TestCronParser_GetRunsBetween(t *testing.T) {
+	parser := NewCronParser()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	runs, err := parser.GetRunsBetween("0 0 * * *", start, end, time.UTC, 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Both ends of the range are included
+	expected := []time.Time{
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+	}
+
+	if len(runs) != len(expected) {
+		t.Fatalf("Expected %d runs, got %d", len(expected), len(runs))
+	}
+	for i, run := range runs {
+		if !run.Equal(expected[i]) {
+			t.Errorf("Run %d: expected %v, got %v", i, expected[i], run)
+		}
+	}
+
+	if _, err := parser.GetRunsBetween("* * * * *", start, end, time.UTC, 10); err == nil {
+		t.Error("Expected an error when the range holds more than max runs")
+	}
+}
@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/db/store"
+)
+
+// LeaderLeaseName is the lease scheduler instances compete for
+const LeaderLeaseName = "scheduler"
+
+// LeaderElector keeps this instance's claim on the scheduler lease so that
+// only one scheduler schedules jobs at a time
+type LeaderElector struct {
+	leaseStore *store.LeaseStore
+	id         string
+	ttl        time.Duration
+	logger     *zap.Logger
+
+	mu          sync.Mutex
+	leaderUntil time.Time // Local deadline after which we stop assuming leadership
+}
+
+// NewLeaderElector creates an elector that holds the lease as id for ttl at a time
+func NewLeaderElector(leaseStore *store.LeaseStore, id string, ttl time.Duration, logger *zap.Logger) *LeaderElector {
+	return &LeaderElector{
+		leaseStore: leaseStore,
+		id:         id,
+		ttl:        ttl,
+		logger:     logger,
+	}
+}
+
+// ID returns the identity this elector holds the lease under
+func (e *LeaderElector) ID() string {
+	return e.id
+}
+
+// IsLeader reports whether this instance currently holds a valid lease
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return time.Now().Before(e.leaderUntil)
+}
+
+// Run renews the lease several times per TTL until ctx is cancelled, then
+// releases it so another instance can take over immediately
+func (e *LeaderElector) Run(ctx context.Context) {
+	interval := e.ttl / 3
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.release()
+			return
+
+		case <-ticker.C:
+			e.tryAcquire(ctx)
+		}
+	}
+}
+
+// tryAcquire takes or renews the lease and updates our local view of leadership
+func (e *LeaderElector) tryAcquire(ctx context.Context) {
+	// Start the local deadline before the round trip so we never believe we
+	// hold the lease longer than the database does
+	deadline := time.Now().Add(e.ttl)
+
+	_, acquired, err := e.leaseStore.TryAcquire(ctx, LeaderLeaseName, e.id, e.ttl)
+	if err != nil {
+		e.logger.Error("Failed to acquire scheduler lease", zap.Error(err))
+		return
+	}
+
+	wasLeader := e.IsLeader()
+
+	e.mu.Lock()
+	if acquired {
+		e.leaderUntil = deadline
+	} else {
+		e.leaderUntil = time.Time{}
+	}
+	e.mu.Unlock()
+
+	switch {
+	case acquired && !wasLeader:
+		e.logger.Info("Became scheduler leader", zap.String("scheduler_id", e.id))
+	case !acquired && wasLeader:
+		e.logger.Warn("Lost scheduler leadership", zap.String("scheduler_id", e.id))
+	}
+}
+
+// release gives up the lease if we hold it
+func (e *LeaderElector) release() {
+	if !e.IsLeader() {
+		return
+	}
+
+	e.mu.Lock()
+	e.leaderUntil = time.Time{}
+	e.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := e.leaseStore.Release(ctx, LeaderLeaseName, e.id); err != nil {
+		e.logger.Error("Failed to release scheduler lease", zap.Error(err))
+		return
+	}
+
+	e.logger.Info("Released scheduler lease", zap.String("scheduler_id", e.id))
+}
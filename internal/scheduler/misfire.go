@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// Default misfire policy applied when a job doesn't configure its own
+const (
+	DefaultMisfireGrace      = time.Minute
+	DefaultMisfireMaxCatchUp = 10
+)
+
+// MaxMisfireCatchUp bounds how many missed slots a run_all job may replay at once
+const MaxMisfireCatchUp = 1000
+
+// maxMisfireScan is how many missed slots are walked one by one before
+// skipping ahead, so a frequent schedule that was down for weeks stays cheap
+const maxMisfireScan = 10000
+
+// ApplyMisfireDefaults fills in any unset fields of a misfire policy. Jobs
+// run their most recent missed slot by default, as they did before misfire
+// policies existed, so a leader failover that outlasts the grace period
+// doesn't drop a slot.
+func ApplyMisfireDefaults(policy *types.MisfirePolicy) {
+	if policy.Strategy == "" {
+		policy.Strategy = types.MisfireRunOnce
+	}
+	if policy.Grace == 0 {
+		policy.Grace = types.Duration(DefaultMisfireGrace)
+	}
+	if policy.Strategy == types.MisfireRunAll && policy.MaxCatchUp == 0 {
+		policy.MaxCatchUp = DefaultMisfireMaxCatchUp
+	}
+}
+
+// ValidateMisfirePolicy checks that a misfire policy is usable
+func ValidateMisfirePolicy(policy types.MisfirePolicy) error {
+	switch policy.Strategy {
+	case "", types.MisfireSkip, types.MisfireRunOnce, types.MisfireRunAll:
+	default:
+		return fmt.Errorf("unknown misfire strategy '%s'", policy.Strategy)
+	}
+
+	if policy.Grace < 0 {
+		return fmt.Errorf("grace must not be negative")
+	}
+	if policy.MaxCatchUp < 0 || policy.MaxCatchUp > MaxMisfireCatchUp {
+		return fmt.Errorf("max_catch_up must be between 0 and %d", MaxMisfireCatchUp)
+	}
+
+	return nil
+}
+
+// catchUpLimit returns how many of the most recent missed slots a policy runs
+func catchUpLimit(policy types.MisfirePolicy) int {
+	switch policy.Strategy {
+	case types.MisfireRunOnce:
+		return 1
+	case types.MisfireRunAll:
+		return policy.MaxCatchUp
+	default:
+		return 0
+	}
+}
+
+// PlanSlots returns the slots of a job's schedule from first up to now that
+// should get a run, oldest first, along with how many missed slots the
+// misfire policy dropped. Slots no more than the grace period late always
+// run; older ones are missed and handled according to the policy.
+func (cp *CronParser) PlanSlots(cronExpr string, loc *time.Location, policy types.MisfirePolicy, first, now time.Time) ([]time.Time, int, error) {
+	schedule, err := cp.parser.Parse(cronExpr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid cron expression '%s': %w", cronExpr, err)
+	}
+
+	ApplyMisfireDefaults(&policy)
+	slots, missed := planSlots(schedule, loc, policy, first, now)
+	return slots, missed, nil
+}
+
+// planSlots walks the schedule from first to now and applies the misfire policy
+func planSlots(schedule cron.Schedule, loc *time.Location, policy types.MisfirePolicy, first, now time.Time) ([]time.Time, int) {
+	cutoff := now.Add(-time.Duration(policy.Grace))
+	keep := catchUpLimit(policy)
+
+	var missedSlots, onTime []time.Time // missedSlots holds only the most recent keep
+	missed := 0
+
+	slot := first
+	for !slot.IsZero() && !slot.After(now) {
+		if !slot.Before(cutoff) {
+			onTime = append(onTime, slot)
+			slot = nextInLocation(schedule, slot, loc)
+			continue
+		}
+
+		missed++
+		if keep > 0 {
+			missedSlots = append(missedSlots, slot)
+			if len(missedSlots) > keep {
+				missedSlots = missedSlots[1:]
+			}
+		}
+
+		if missed == maxMisfireScan {
+			// Only the most recent missed slots can be kept, so skip to a
+			// point comfortably more than keep slots before the cutoff and
+			// estimate how many slots were passed over
+			spacing := slot.Sub(first) / time.Duration(missed-1)
+			target := cutoff.Add(-spacing * time.Duration(2*keep+2))
+			if spacing > 0 && target.After(slot) {
+				missed += int(target.Sub(slot) / spacing)
+				slot = nextInLocation(schedule, target, loc)
+				continue
+			}
+		}
+
+		slot = nextInLocation(schedule, slot, loc)
+	}
+
+	return append(missedSlots, onTime...), missed - len(missedSlots)
+}
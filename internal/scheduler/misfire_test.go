@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+func TestCronParser_PlanSlots(t *testing.T) {
+	parser := NewCronParser()
+
+	// Hourly job whose 06:00 slot was due, scheduler back at 12:00:30
+	first := time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 1, 1, 12, 0, 30, 0, time.UTC)
+
+	hour := func(h int) time.Time {
+		return time.Date(2024, 1, 1, h, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		policy     types.MisfirePolicy
+		wantSlots  []time.Time
+		wantMissed int
+	}{
+		{
+			name:       "skip runs only the on-time slot",
+			policy:     types.MisfirePolicy{Strategy: types.MisfireSkip},
+			wantSlots:  []time.Time{hour(12)},
+			wantMissed: 6,
+		},
+		{
+			name:       "empty policy defaults to run_once",
+			policy:     types.MisfirePolicy{},
+			wantSlots:  []time.Time{hour(11), hour(12)},
+			wantMissed: 5,
+		},
+		{
+			name:       "run_once keeps the most recent missed slot",
+			policy:     types.MisfirePolicy{Strategy: types.MisfireRunOnce},
+			wantSlots:  []time.Time{hour(11), hour(12)},
+			wantMissed: 5,
+		},
+		{
+			name:       "run_all replays every missed slot",
+			policy:     types.MisfirePolicy{Strategy: types.MisfireRunAll},
+			wantSlots:  []time.Time{hour(6), hour(7), hour(8), hour(9), hour(10), hour(11), hour(12)},
+			wantMissed: 0,
+		},
+		{
+			name:       "run_all is capped to the most recent slots",
+			policy:     types.MisfirePolicy{Strategy: types.MisfireRunAll, MaxCatchUp: 2},
+			wantSlots:  []time.Time{hour(10), hour(11), hour(12)},
+			wantMissed: 4,
+		},
+		{
+			name:       "slots within grace are not missed",
+			policy:     types.MisfirePolicy{Strategy: types.MisfireSkip, Grace: types.Duration(2*time.Hour + time.Minute)},
+			wantSlots:  []time.Time{hour(10), hour(11), hour(12)},
+			wantMissed: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slots, missed, err := parser.PlanSlots("0 * * * *", time.UTC, tt.policy, first, now)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if missed != tt.wantMissed {
+				t.Errorf("Expected %d missed slots, got %d", tt.wantMissed, missed)
+			}
+
+			if len(slots) != len(tt.wantSlots) {
+				t.Fatalf("Expected slots %v, got %v", tt.wantSlots, slots)
+			}
+			for i, slot := range slots {
+				if !slot.Equal(tt.wantSlots[i]) {
+					t.Errorf("Slot %d: expected %v, got %v", i, tt.wantSlots[i], slot)
+				}
+			}
+		})
+	}
+}
+
+func TestCronParser_PlanSlots_LeaderFailover(t *testing.T) {
+	parser := NewCronParser()
+
+	// The leader died just before the 10:00 slot. Its lease ran out after
+	// 30s and the next instance took over at its following check, well past
+	// the grace period.
+	first := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	now := first.Add(DefaultMisfireGrace + 30*time.Second + 10*time.Second + 30*time.Second)
+
+	slots, missed, err := parser.PlanSlots("0 * * * *", time.UTC, types.MisfirePolicy{}, first, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(slots) != 1 || !slots[0].Equal(first) || missed != 0 {
+		t.Errorf("Expected the slot missed during failover to run, got slots %v and %d missed", slots, missed)
+	}
+}
+
+func TestCronParser_PlanSlots_LongOutage(t *testing.T) {
+	parser := NewCronParser()
+
+	// A per-minute job down for a year must not walk every slot
+	first := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 1, 1, 0, 0, 10, 0, time.UTC)
+	policy := types.MisfirePolicy{Strategy: types.MisfireRunAll, MaxCatchUp: 3}
+
+	slots, missed, err := parser.PlanSlots("* * * * *", time.UTC, policy, first, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []time.Time{
+		time.Date(2023, 12, 31, 23, 57, 0, 0, time.UTC),
+		time.Date(2023, 12, 31, 23, 58, 0, 0, time.UTC),
+		time.Date(2023, 12, 31, 23, 59, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if len(slots) != len(want) {
+		t.Fatalf("Expected slots %v, got %v", want, slots)
+	}
+	for i, slot := range slots {
+		if !slot.Equal(want[i]) {
+			t.Errorf("Slot %d: expected %v, got %v", i, want[i], slot)
+		}
+	}
+
+	// 365 days of minutes, less the three replayed slots (the estimate is
+	// exact for a regular schedule)
+	if wantMissed := 365*24*60 - 3; missed != wantMissed {
+		t.Errorf("Expected %d missed slots, got %d", wantMissed, missed)
+	}
+}
+
+func TestValidateMisfirePolicy(t *testing.T) {
+	valid := []types.MisfirePolicy{
+		{},
+		{Strategy: types.MisfireRunOnce, Grace: types.Duration(time.Minute)},
+		{Strategy: types.MisfireRunAll, MaxCatchUp: MaxMisfireCatchUp},
+	}
+	for _, policy := range valid {
+		if err := ValidateMisfirePolicy(policy); err != nil {
+			t.Errorf("Expected %+v to be valid, got error: %v", policy, err)
+		}
+	}
+
+	invalid := []types.MisfirePolicy{
+		{Strategy: "sometimes"},
+		{Grace: types.Duration(-time.Second)},
+		{Strategy: types.MisfireRunAll, MaxCatchUp: -1},
+		{Strategy: types.MisfireRunAll, MaxCatchUp: MaxMisfireCatchUp + 1},
+	}
+	for _, policy := range invalid {
+		if err := ValidateMisfirePolicy(policy); err == nil {
+			t.Errorf("Expected %+v to be invalid", policy)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/db/store"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// DefaultRunHeartbeatTimeout is how long an in-flight run may go without a
+// heartbeat before its worker is considered lost
+const DefaultRunHeartbeatTimeout = time.Minute
+
+// workerLostError is recorded on runs whose worker stopped heartbeating
+const workerLostError = "worker lost: no heartbeat received"
+
+// SetRunHeartbeatTimeout configures how long a run may go without a heartbeat
+func (s *Scheduler) SetRunHeartbeatTimeout(timeout time.Duration) {
+	s.runHeartbeatTimeout = timeout
+}
+
+// reapOrphanedRuns fails runs whose worker stopped heartbeating and queues
+// their retries in the same transaction, so a crash can't lose either
+func (s *Scheduler) reapOrphanedRuns(ctx context.Context, now time.Time) error {
+	var reaped, retries []*types.Run
+
+	err := store.RunInTx(ctx, s.db, func(tx pgx.Tx) error {
+		runStore := s.runStore.WithTx(tx)
+		jobStore := s.jobStore.WithTx(tx)
+
+		var err error
+		reaped, err = runStore.FailOrphanedRuns(ctx, now.Add(-s.runHeartbeatTimeout), workerLostError)
+		if err != nil {
+			return err
+		}
+
+		for _, run := range reaped {
+			job, err := jobStore.GetJob(ctx, run.JobID)
+			if err != nil {
+				return fmt.Errorf("failed to get job for orphaned run %s: %w", run.ID, err)
+			}
+
+			retry := NewRetryRun(job, run, now)
+			if retry == nil {
+				continue
+			}
+			if err := runStore.CreateRun(ctx, retry); err != nil {
+				return fmt.Errorf("failed to retry orphaned run %s: %w", run.ID, err)
+			}
+			retries = append(retries, retry)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, run := range reaped {
+		var claimedBy string
+		if run.ClaimedBy != nil {
+			claimedBy = *run.ClaimedBy
+		}
+
+		s.logger.Warn("Failed run whose worker was lost",
+			zap.String("run_id", run.ID.String()),
+			zap.String("job_id", run.JobID.String()),
+			zap.String("claimed_by", claimedBy),
+			zap.Int("attempt_num", run.AttemptNum))
+	}
+
+	for _, retry := range retries {
+		s.logger.Info("Scheduled retry for orphaned run",
+			zap.String("run_id", retry.ID.String()),
+			zap.String("retry_of", retry.RetryOf.String()),
+			zap.Int("attempt_num", retry.AttemptNum),
+			zap.Time("scheduled_at", retry.ScheduledAt))
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+// Default retry policy applied when a job doesn't configure its own
+const (
+	DefaultRetryInitialDelay = 30 * time.Second
+	DefaultRetryMaxDelay     = time.Hour
+	DefaultRetryMultiplier   = 2.0
+)
+
+// randInt63n is swapped out in tests to make jitter deterministic
+var randInt63n = rand.Int63n
+
+// ApplyRetryDefaults fills in any unset fields of a retry policy
+func ApplyRetryDefaults(policy *types.RetryPolicy) {
+	if policy.Strategy == "" {
+		policy.Strategy = types.BackoffExponential
+	}
+	if policy.InitialDelay == 0 {
+		policy.InitialDelay = types.Duration(DefaultRetryInitialDelay)
+	}
+	if policy.MaxDelay == 0 {
+		policy.MaxDelay = types.Duration(DefaultRetryMaxDelay)
+	}
+	if policy.Strategy == types.BackoffExponential && policy.Multiplier == 0 {
+		policy.Multiplier = DefaultRetryMultiplier
+	}
+}
+
+// ValidateRetryPolicy checks that a retry policy is usable
+func ValidateRetryPolicy(policy types.RetryPolicy) error {
+	switch policy.Strategy {
+	case "", types.BackoffFixed, types.BackoffLinear, types.BackoffExponential:
+	default:
+		return fmt.Errorf("unknown backoff strategy '%s'", policy.Strategy)
+	}
+
+	if policy.InitialDelay < 0 || policy.MaxDelay < 0 {
+		return fmt.Errorf("retry delays must not be negative")
+	}
+	if policy.InitialDelay > 0 && policy.MaxDelay > 0 && policy.MaxDelay < policy.InitialDelay {
+		return fmt.Errorf("max_delay must not be smaller than initial_delay")
+	}
+	if policy.Multiplier != 0 && policy.Multiplier < 1 {
+		return fmt.Errorf("multiplier must be at least 1")
+	}
+
+	return nil
+}
+
+// IsRetryable reports whether a run that ended with status should be retried
+func IsRetryable(status types.RunStatus) bool {
+	switch status {
+	case types.RunStatusFailed, types.RunStatusTimedOut, types.RunStatusInterrupted:
+		return true
+	default:
+		return false
+	}
+}
+
+// RetryDelay returns how long to wait before the nth retry (starting at 1)
+func RetryDelay(policy types.RetryPolicy, retry int) time.Duration {
+	ApplyRetryDefaults(&policy)
+
+	if retry < 1 {
+		retry = 1
+	}
+
+	initialDelay, maxDelay := time.Duration(policy.InitialDelay), time.Duration(policy.MaxDelay)
+
+	var delay time.Duration
+	switch policy.Strategy {
+	case types.BackoffFixed:
+		delay = initialDelay
+	case types.BackoffLinear:
+		delay = initialDelay * time.Duration(retry)
+	default:
+		growth := math.Pow(policy.Multiplier, float64(retry-1))
+		delay = time.Duration(math.Min(float64(initialDelay)*growth, float64(maxDelay)))
+	}
+
+	if delay > maxDelay || delay < 0 {
+		delay = maxDelay
+	}
+
+	// Equal jitter: keep half the delay and randomize the other half
+	if policy.Jitter && delay > 1 {
+		half := delay / 2
+		delay = half + time.Duration(randInt63n(int64(delay-half)+1))
+	}
+
+	return delay
+}
+
+// NewRetryRun builds the follow-up run for a failed run, or returns nil when
+// the job has no retries left
+func NewRetryRun(job *types.Job, failed *types.Run, now time.Time) *types.Run {
+	// Attempt 1 is the original run, so MaxRetries retries end at MaxRetries+1
+	if failed.AttemptNum > job.MaxRetries {
+		return nil
+	}
+
+	retryOf := failed.ID
+	return &types.Run{
+		JobID:       job.ID,
+		Status:      types.RunStatusScheduled,
+		AttemptNum:  failed.AttemptNum + 1,
+		RetryOf:     &retryOf,
+		Trigger:     failed.Trigger, // Retries count towards whatever created the run
+		BackfillID:  failed.BackfillID,
+		TriggeredBy: failed.TriggeredBy,
+		Overrides:   failed.Overrides, // A retry repeats the same invocation
+		ScheduledAt: now.Add(RetryDelay(job.RetryPolicy, failed.AttemptNum)),
+	}
+}
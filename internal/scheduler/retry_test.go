@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy types.RetryPolicy
+		retry  int
+		want   time.Duration
+	}{
+		{
+			name:   "fixed",
+			policy: types.RetryPolicy{Strategy: types.BackoffFixed, InitialDelay: types.Duration(10 * time.Second)},
+			retry:  3,
+			want:   10 * time.Second,
+		},
+		{
+			name:   "linear",
+			policy: types.RetryPolicy{Strategy: types.BackoffLinear, InitialDelay: types.Duration(10 * time.Second)},
+			retry:  3,
+			want:   30 * time.Second,
+		},
+		{
+			name:   "exponential",
+			policy: types.RetryPolicy{Strategy: types.BackoffExponential, InitialDelay: types.Duration(10 * time.Second), Multiplier: 2},
+			retry:  4,
+			want:   80 * time.Second,
+		},
+		{
+			name:   "exponential capped at max delay",
+			policy: types.RetryPolicy{Strategy: types.BackoffExponential, InitialDelay: types.Duration(10 * time.Second), MaxDelay: types.Duration(time.Minute)},
+			retry:  10,
+			want:   time.Minute,
+		},
+		{
+			name:   "linear capped at max delay",
+			policy: types.RetryPolicy{Strategy: types.BackoffLinear, InitialDelay: types.Duration(time.Minute), MaxDelay: types.Duration(2 * time.Minute)},
+			retry:  5,
+			want:   2 * time.Minute,
+		},
+		{
+			name:   "defaults for empty policy",
+			policy: types.RetryPolicy{},
+			retry:  2,
+			want:   2 * DefaultRetryInitialDelay,
+		},
+		{
+			name:   "huge retry number does not overflow",
+			policy: types.RetryPolicy{Strategy: types.BackoffExponential, InitialDelay: types.Duration(time.Second), MaxDelay: types.Duration(time.Hour)},
+			retry:  5000,
+			want:   time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RetryDelay(tt.policy, tt.retry); got != tt.want {
+				t.Errorf("RetryDelay() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelay_Jitter(t *testing.T) {
+	original := randInt63n
+	defer func() { randInt63n = original }()
+
+	policy := types.RetryPolicy{Strategy: types.BackoffFixed, InitialDelay: types.Duration(10 * time.Second), Jitter: true}
+
+	// Lowest possible jitter keeps half the delay
+	randInt63n = func(n int64) int64 { return 0 }
+	if got := RetryDelay(policy, 1); got != 5*time.Second {
+		t.Errorf("Expected minimum jittered delay 5s, got %s", got)
+	}
+
+	// Highest possible jitter restores the full delay
+	randInt63n = func(n int64) int64 { return n - 1 }
+	if got := RetryDelay(policy, 1); got != 10*time.Second {
+		t.Errorf("Expected maximum jittered delay 10s, got %s", got)
+	}
+}
+
+func TestValidateRetryPolicy(t *testing.T) {
+	valid := []types.RetryPolicy{
+		{},
+		{Strategy: types.BackoffFixed, InitialDelay: types.Duration(time.Second)},
+		{Strategy: types.BackoffExponential, InitialDelay: types.Duration(time.Second), MaxDelay: types.Duration(time.Minute), Multiplier: 1.5, Jitter: true},
+	}
+	for _, policy := range valid {
+		if err := ValidateRetryPolicy(policy); err != nil {
+			t.Errorf("Expected %+v to be valid, got error: %v", policy, err)
+		}
+	}
+
+	invalid := []types.RetryPolicy{
+		{Strategy: "random"},
+		{InitialDelay: types.Duration(-time.Second)},
+		{InitialDelay: types.Duration(time.Minute), MaxDelay: types.Duration(time.Second)},
+		{Strategy: types.BackoffExponential, Multiplier: 0.5},
+	}
+	for _, policy := range invalid {
+		if err := ValidateRetryPolicy(policy); err == nil {
+			t.Errorf("Expected %+v to be invalid, but validation passed", policy)
+		}
+	}
+}
+
+func TestNewRetryRun(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	job := &types.Job{
+		ID:          uuid.New(),
+		MaxRetries:  2,
+		RetryPolicy: types.RetryPolicy{Strategy: types.BackoffFixed, InitialDelay: types.Duration(time.Minute)},
+	}
+
+	backfillID := uuid.New()
+	failed := &types.Run{
+		ID:         uuid.New(),
+		JobID:      job.ID,
+		Status:     types.RunStatusFailed,
+		AttemptNum: 1,
+		Trigger:    types.RunTriggerBackfill,
+		BackfillID: &backfillID,
+	}
+
+	retry := NewRetryRun(job, failed, now)
+	if retry == nil {
+		t.Fatal("Expected a retry run for the first attempt")
+	}
+
+	if retry.AttemptNum != 2 {
+		t.Errorf("Expected attempt 2, got %d", retry.AttemptNum)
+	}
+	if retry.RetryOf == nil || *retry.RetryOf != failed.ID {
+		t.Errorf("Expected retry to link to %s, got %v", failed.ID, retry.RetryOf)
+	}
+	if retry.Status != types.RunStatusScheduled {
+		t.Errorf("Expected status %s, got %s", types.RunStatusScheduled, retry.Status)
+	}
+	if !retry.ScheduledAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected retry at %v, got %v", now.Add(time.Minute), retry.ScheduledAt)
+	}
+	if retry.Trigger != types.RunTriggerBackfill || retry.BackfillID == nil || *retry.BackfillID != backfillID {
+		t.Errorf("Expected retry to stay in backfill %s, got %s %v", backfillID, retry.Trigger, retry.BackfillID)
+	}
+
+	// Manual runs keep who triggered them and their overrides
+	timeout := types.Duration(5 * time.Second)
+	triggeredBy := "alice"
+	failed.Trigger = types.RunTriggerManual
+	failed.BackfillID = nil
+	failed.TriggeredBy = &triggeredBy
+	failed.Overrides = &types.RunOverrides{Args: []string{"--dry-run"}, Timeout: &timeout}
+
+	retry = NewRetryRun(job, failed, now)
+	if retry.Trigger != types.RunTriggerManual || retry.TriggeredBy == nil || *retry.TriggeredBy != triggeredBy {
+		t.Errorf("Expected manual retry triggered by %s, got %s %v", triggeredBy, retry.Trigger, retry.TriggeredBy)
+	}
+	if retry.Overrides != failed.Overrides {
+		t.Errorf("Expected retry to keep overrides %+v, got %+v", failed.Overrides, retry.Overrides)
+	}
+
+	// Attempt 3 is the last retry allowed by MaxRetries = 2
+	failed.AttemptNum = 3
+	if retry := NewRetryRun(job, failed, now); retry != nil {
+		t.Errorf("Expected no retry after %d attempts, got attempt %d", failed.AttemptNum, retry.AttemptNum)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+
 	"github.com/Franklyne-kibet/aster-scheduler/internal/db/store"
 	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
 	"go.uber.org/zap"
@@ -12,23 +14,28 @@ import (
 
 // Scheduler is responsible for finding due jobs and scheduling them for execution
 type Scheduler struct {
+	db         store.DBTX // Used to schedule runs transactionally
 	jobStore   *store.JobStore
 	runStore   *store.RunStore
 	cronParser *CronParser
+	elector    *LeaderElector // nil when this is the only scheduler
 	logger     *zap.Logger
 
 	// Configuration
-	checkInterval time.Duration
+	checkInterval       time.Duration
+	runHeartbeatTimeout time.Duration // Runs silent for longer are reaped
 }
 
 // NewScheduler creates a new scheduler instance
-func NewScheduler(jobStore *store.JobStore, runStore *store.RunStore, logger *zap.Logger) *Scheduler {
+func NewScheduler(db store.DBTX, jobStore *store.JobStore, runStore *store.RunStore, logger *zap.Logger) *Scheduler {
 	return &Scheduler{
-		jobStore:      jobStore,
-		runStore:      runStore,
-		cronParser:    NewCronParser(),
-		logger:        logger,
-		checkInterval: 30 * time.Second, // Check every 30 seconds by default
+		db:                  db,
+		jobStore:            jobStore,
+		runStore:            runStore,
+		cronParser:          NewCronParser(),
+		logger:              logger,
+		checkInterval:       30 * time.Second, // Check every 30 seconds by default
+		runHeartbeatTimeout: DefaultRunHeartbeatTimeout,
 	}
 }
 
@@ -37,15 +44,47 @@ func (s *Scheduler) SetCheckInterval(interval time.Duration) {
 	s.checkInterval = interval
 }
 
-// Run starts the schedular in a loop (this is a blocking operation)
+// SetLeaderElector makes the scheduler only schedule jobs while it holds the
+// leader lease. Without an elector the scheduler assumes it is the only instance.
+func (s *Scheduler) SetLeaderElector(elector *LeaderElector) {
+	s.elector = elector
+}
+
+// isLeader reports whether this instance should schedule jobs
+func (s *Scheduler) isLeader() bool {
+	return s.elector == nil || s.elector.IsLeader()
+}
+
+// Run starts the schedular in a loop (this is a blocking operation). When
+// ctx is cancelled a tick in progress is allowed to finish, and the leader
+// lease is released only after it has.
 func (s *Scheduler) Run(ctx context.Context) error {
 	s.logger.Info("Starting scheduler", zap.Duration("check_interval", s.checkInterval))
 
 	ticker := time.NewTicker(s.checkInterval)
 	defer ticker.Stop()
 
+	// Ticks don't see the shutdown, so one never stops halfway
+	tickCtx := context.WithoutCancel(ctx)
+
+	// Compete for leadership before the first check
+	if s.elector != nil {
+		s.elector.tryAcquire(ctx)
+
+		electorCtx, stopElector := context.WithCancel(tickCtx)
+		electorDone := make(chan struct{})
+		go func() {
+			s.elector.Run(electorCtx)
+			close(electorDone)
+		}()
+		defer func() {
+			stopElector()
+			<-electorDone
+		}()
+	}
+
 	// Do an initial check immediately
-	if err := s.checkAndScheduleDueJobs(ctx); err != nil {
+	if err := s.tick(tickCtx); err != nil {
 		s.logger.Error("Error in initial job check", zap.Error(err))
 	}
 
@@ -57,7 +96,7 @@ func (s *Scheduler) Run(ctx context.Context) error {
 
 		case <-ticker.C:
 			// Time to check for due jobs
-			if err := s.checkAndScheduleDueJobs(ctx); err != nil {
+			if err := s.tick(tickCtx); err != nil {
 				s.logger.Error("Error checking for due jobs", zap.Error(err))
 				// Don't return error - keep running
 			}
@@ -65,6 +104,22 @@ func (s *Scheduler) Run(ctx context.Context) error {
 	}
 }
 
+// tick runs one scheduling pass if this instance is the leader, first
+// reaping runs whose worker stopped heartbeating
+func (s *Scheduler) tick(ctx context.Context) error {
+	if !s.isLeader() {
+		s.logger.Debug("Not the leader, skipping job check")
+		return nil
+	}
+
+	// Recover runs from crashed workers before scheduling new ones
+	if err := s.reapOrphanedRuns(ctx, time.Now()); err != nil {
+		s.logger.Error("Failed to reap orphaned runs", zap.Error(err))
+	}
+
+	return s.checkAndScheduleDueJobs(ctx)
+}
+
 // checkAndScheduleDueJobs finds jobs that are due and schedules them
 func (s *Scheduler) checkAndScheduleDueJobs(ctx context.Context) error {
 	now := time.Now()
@@ -97,36 +152,113 @@ func (s *Scheduler) checkAndScheduleDueJobs(ctx context.Context) error {
 	return nil
 }
 
-// scheduleJob creates a run for a job and calculates the next run time
-func (s *Scheduler) scheduleJob(ctx context.Context, job *types.Job, scheduledAt time.Time) error {
-	// Create a new run for this job
-	run := &types.Run{
-		JobID:       job.ID,
-		Status:      types.RunStatusScheduled,
-		AttemptNum:  1, // This is the first attempt
-		ScheduledAt: scheduledAt,
+// scheduleJob creates runs for a job's due slots and advances next_run_at in
+// a single transaction. Slots missed while no scheduler was running are caught
+// up according to the job's misfire policy, and runs still active from
+// earlier slots are handled according to its concurrency policy. If a slot
+// already has a run (for example because a previous attempt crashed after
+// committing) no duplicate is created.
+func (s *Scheduler) scheduleJob(ctx context.Context, job *types.Job, now time.Time) error {
+	loc, err := LoadLocation(job.Timezone)
+	if err != nil {
+		return fmt.Errorf("failed to load time zone for job %s: %w", job.Name, err)
 	}
 
-	// Create the run in the database
-	if err := s.runStore.CreateRun(ctx, run); err != nil {
-		return fmt.Errorf("failed to create run for job %s: %w", job.Name, err)
-	}
+	// Each run is scheduled for the slot that fell due, which identifies it
+	// across retries of this tick. New jobs without a slot run immediately.
+	policy := job.MisfirePolicy
+	ApplyMisfireDefaults(&policy)
 
-	s.logger.Info("Created run for job",
-		zap.String("job_id", job.ID.String()),
-		zap.String("job_name", job.Name),
-		zap.String("run_id", run.ID.String()),
-		zap.Time("scheduled_at", scheduledAt))
+	slots := []time.Time{now}
+	missed := 0
+	if job.NextRunAt != nil {
+		slots, missed, err = s.cronParser.PlanSlots(job.CronExpr, loc, policy, *job.NextRunAt, now)
+		if err != nil {
+			return fmt.Errorf("failed to plan runs for job %s: %w", job.Name, err)
+		}
+	}
 
-	// Calculate next run time
-	nextRunAt, err := s.cronParser.ParserAndNext(job.CronExpr, scheduledAt)
+	// Calculate next run time in the job's time zone before writing anything
+	nextRunAt, err := s.cronParser.NextInLocation(job.CronExpr, now, loc)
 	if err != nil {
 		return fmt.Errorf("failed to calculate next run time for job %s: %w", job.Name, err)
 	}
 
-	// Update the job's next_run_at field
-	if err := s.jobStore.UpdateJobNextRunAt(ctx, job.ID, &nextRunAt); err != nil {
-		return fmt.Errorf("failed to update next run time for job %s: %w", job.Name, err)
+	runs := make([]*types.Run, len(slots))
+	created := make([]bool, len(slots))
+	for i, slot := range slots {
+		runs[i] = &types.Run{
+			JobID:       job.ID,
+			Status:      types.RunStatusScheduled,
+			AttemptNum:  1, // This is the first attempt
+			Trigger:     types.RunTriggerSchedule,
+			ScheduledAt: slot,
+		}
+	}
+
+	var skipped bool
+	err = store.RunInTx(ctx, s.db, func(tx pgx.Tx) error {
+		runStore := s.runStore.WithTx(tx)
+
+		var err error
+		skipped, err = s.applyConcurrencyPolicy(ctx, runStore, job)
+		if err != nil {
+			return err
+		}
+
+		for i, run := range runs {
+			if skipped {
+				// Record the slot so it's visible why it didn't run
+				errorMsg := "skipped: previous run still active"
+				run.Status = types.RunStatusSkipped
+				run.FinishedAt = &now
+				run.ErrorMsg = &errorMsg
+			}
+
+			created[i], err = runStore.CreateRunOnce(ctx, run)
+			if err != nil {
+				return fmt.Errorf("failed to create run for job %s: %w", job.Name, err)
+			}
+		}
+
+		// Update the job's next_run_at field
+		if err := s.jobStore.WithTx(tx).UpdateJobNextRunAt(ctx, job.ID, &nextRunAt); err != nil {
+			return fmt.Errorf("failed to update next run time for job %s: %w", job.Name, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if missed > 0 {
+		s.logger.Warn("Dropped missed slots for job",
+			zap.String("job_id", job.ID.String()),
+			zap.String("job_name", job.Name),
+			zap.String("misfire_policy", string(policy.Strategy)),
+			zap.Int("missed_slots", missed))
+	}
+
+	for i, run := range runs {
+		if created[i] && skipped {
+			s.logger.Warn("Skipped slot, previous run still active",
+				zap.String("job_id", job.ID.String()),
+				zap.String("job_name", job.Name),
+				zap.String("run_id", run.ID.String()),
+				zap.Time("scheduled_at", run.ScheduledAt))
+		} else if created[i] {
+			s.logger.Info("Created run for job",
+				zap.String("job_id", job.ID.String()),
+				zap.String("job_name", job.Name),
+				zap.String("run_id", run.ID.String()),
+				zap.Time("scheduled_at", run.ScheduledAt))
+		} else {
+			s.logger.Warn("Run already exists for slot, not creating another",
+				zap.String("job_id", job.ID.String()),
+				zap.String("job_name", job.Name),
+				zap.Time("scheduled_at", run.ScheduledAt))
+		}
 	}
 
 	s.logger.Debug("Updated next run time",
@@ -136,7 +268,12 @@ func (s *Scheduler) scheduleJob(ctx context.Context, job *types.Job, scheduledAt
 	return nil
 }
 
-// GetJobNextRuns returns the next N run times for a job
-func (s *Scheduler) GetJobNextRuns(cronExpr string, fromTime time.Time, n int) ([]time.Time, error) {
-	return s.cronParser.GetNextNRuns(cronExpr, fromTime, n)
+// GetJobNextRuns returns the next N run times for a job in its time zone
+func (s *Scheduler) GetJobNextRuns(job *types.Job, fromTime time.Time, n int) ([]time.Time, error) {
+	loc, err := LoadLocation(job.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.cronParser.GetNextNRunsInLocation(job.CronExpr, fromTime, loc, n)
 }
@@ -0,0 +1,18 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Backfill is a request to run a job for every cron slot in a past time range
+type Backfill struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	JobID       uuid.UUID `json:"job_id" db:"job_id"`
+	StartAt     time.Time `json:"start" db:"start_at"`
+	EndAt       time.Time `json:"end" db:"end_at"`
+	Parallelism int       `json:"parallelism" db:"parallelism"` // Max runs of this backfill executing at once
+	RunCount    int       `json:"run_count" db:"run_count"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Duration is a time.Duration written to JSON as a duration string such as
+// "1m30s". Integer nanoseconds are still read, as older policies and job
+// timeouts were sent that way.
+type Duration time.Duration
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// String formats the duration like time.Duration
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// UnmarshalJSON reads a duration string or integer nanoseconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", v, err)
+		}
+		*d = Duration(parsed)
+	case float64:
+		*d = Duration(v)
+	case nil:
+	default:
+		return fmt.Errorf("invalid duration %s: must be a string such as \"30s\"", data)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDuration_JSON(t *testing.T) {
+	data, err := json.Marshal(RetryPolicy{InitialDelay: Duration(30 * time.Second), MaxDelay: Duration(time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if want := `{"strategy":"","initial_delay":"30s","max_delay":"1h0m0s","jitter":false}`; string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+
+	tests := []struct {
+		input   string
+		want    Duration
+		wantErr bool
+	}{
+		{`"1m30s"`, Duration(90 * time.Second), false},
+		{`"-1s"`, Duration(-time.Second), false},
+		{`60000000000`, Duration(time.Minute), false}, // Stored by earlier versions
+		{`null`, 0, false},
+		{`"30"`, 0, true},
+		{`true`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var d Duration
+			err := json.Unmarshal([]byte(tt.input), &d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if d != tt.want {
+				t.Errorf("Expected %v, got %v", time.Duration(tt.want), time.Duration(d))
+			}
+		})
+	}
+}
+
+func TestJob_TimeoutJSON(t *testing.T) {
+	// A job and a run's overrides take the same timeout format
+	for _, input := range []string{`{"timeout":"5m"}`, `{"timeout":300000000000}`} {
+		var job Job
+		if err := json.Unmarshal([]byte(input), &job); err != nil {
+			t.Fatalf("Failed to unmarshal job %s: %v", input, err)
+		}
+		var overrides RunOverrides
+		if err := json.Unmarshal([]byte(input), &overrides); err != nil {
+			t.Fatalf("Failed to unmarshal overrides %s: %v", input, err)
+		}
+		if job.Timeout == nil || overrides.Timeout == nil || *job.Timeout != *overrides.Timeout || *job.Timeout != Duration(5*time.Minute) {
+			t.Errorf("Expected both timeouts to be 5m from %s, got %v and %v", input, job.Timeout, overrides.Timeout)
+		}
+	}
+
+	timeout := Duration(5 * time.Minute)
+	data, err := json.Marshal(Job{Timeout: &timeout})
+	if err != nil {
+		t.Fatalf("Failed to marshal job: %v", err)
+	}
+	if !strings.Contains(string(data), `"timeout":"5m0s"`) {
+		t.Errorf("Expected timeout written as a duration string, got %s", data)
+	}
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,45 +18,142 @@ const (
 // Job represents a scheduled task
 // struct tags to convert to/from JSON and DB
 type Job struct {
-	ID          uuid.UUID         `json:"id" db:"id"`
-	Name        string            `json:"name" db:"name"`
-	Description string            `json:"description" db:"description"`
-	CronExpr    string            `json:"cron_expr" db:"cron_expr"`
-	Command     string            `json:"command" db:"command"`
-	Args        []string          `json:"args" db:"args"`
-	Env         map[string]string `json:"env" db:"env"`
-	Status      JobStatus         `json:"status" db:"status"`
-	MaxRetries  int               `json:"max_retries" db:"max_retries"`
-	Timeout     *time.Duration    `json:"timeout,omitempty" db:"timeout"`
-	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
-	NextRunAt   *time.Time        `json:"next_run_at,omitempty" db:"next_run_at"`
+	ID                uuid.UUID         `json:"id" db:"id"`
+	Name              string            `json:"name" db:"name"`
+	Description       string            `json:"description" db:"description"`
+	CronExpr          string            `json:"cron_expr" db:"cron_expr"`
+	Timezone          string            `json:"timezone" db:"timezone"`       // IANA zone the cron expression is evaluated in
+	Type              string            `json:"type" db:"type"`               // Executor that runs the job, "command" by default
+	Config            json.RawMessage   `json:"config,omitempty" db:"config"` // Settings specific to the job's type
+	Command           string            `json:"command" db:"command"`
+	Args              []string          `json:"args" db:"args"`
+	Env               map[string]string `json:"env" db:"env"`
+	Status            JobStatus         `json:"status" db:"status"`
+	MaxRetries        int               `json:"max_retries" db:"max_retries"`
+	RetryPolicy       RetryPolicy       `json:"retry_policy" db:"retry_policy"`
+	MisfirePolicy     MisfirePolicy     `json:"misfire_policy" db:"misfire_policy"`
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrency_policy" db:"concurrency_policy"`
+	MaxConcurrentRuns int               `json:"max_concurrent_runs,omitempty" db:"max_concurrent_runs"` // 0 means no limit
+	Timeout           *Duration         `json:"timeout,omitempty" db:"timeout"`
+	CreatedAt         time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at" db:"updated_at"`
+	NextRunAt         *time.Time        `json:"next_run_at,omitempty" db:"next_run_at"`
 }
 
+// BackoffStrategy controls how the delay between retries grows
+type BackoffStrategy string
+
+const (
+	BackoffFixed       BackoffStrategy = "fixed"
+	BackoffLinear      BackoffStrategy = "linear"
+	BackoffExponential BackoffStrategy = "exponential"
+)
+
+// RetryPolicy describes when a failed run is retried
+type RetryPolicy struct {
+	Strategy     BackoffStrategy `json:"strategy"`
+	InitialDelay Duration        `json:"initial_delay"`
+	MaxDelay     Duration        `json:"max_delay"`
+	Multiplier   float64         `json:"multiplier,omitempty"` // Exponential growth factor
+	Jitter       bool            `json:"jitter"`               // Randomize delays to spread retries out
+}
+
+// MisfireStrategy controls what happens to schedule slots that were missed,
+// for example while no scheduler was running
+type MisfireStrategy string
+
+const (
+	MisfireSkip    MisfireStrategy = "skip"     // Drop missed slots
+	MisfireRunOnce MisfireStrategy = "run_once" // Run only the most recent missed slot
+	MisfireRunAll  MisfireStrategy = "run_all"  // Run missed slots, up to MaxCatchUp of them
+)
+
+// MisfirePolicy describes how missed schedule slots are caught up
+type MisfirePolicy struct {
+	Strategy   MisfireStrategy `json:"strategy"`
+	Grace      Duration        `json:"grace"`                  // How late a slot may start before it counts as missed
+	MaxCatchUp int             `json:"max_catch_up,omitempty"` // run_all only: how many of the most recent missed slots to run
+}
+
+// ConcurrencyPolicy controls what happens when a job's slot comes due while
+// an earlier run of the job is still active
+type ConcurrencyPolicy string
+
+const (
+	ConcurrencyAllow   ConcurrencyPolicy = "allow"   // Let runs overlap
+	ConcurrencyForbid  ConcurrencyPolicy = "forbid"  // Skip the new slot
+	ConcurrencyReplace ConcurrencyPolicy = "replace" // Cancel the active run and start the new one
+)
+
 // RunStatus represents the state of a single execution
 type RunStatus string
 
 const (
-	RunStatusScheduled RunStatus = "scheduled"
-	RunStatusClaimed   RunStatus = "claimed"
-	RunStatusRunning   RunStatus = "running"
-	RunStatusSucceeded RunStatus = "succeeded"
-	RunStatusFailed    RunStatus = "failed"
-	RunStatusCancelled RunStatus = "cancelled"
-	RunStatusTimedOut  RunStatus = "timed_out"
+	RunStatusScheduled     RunStatus = "scheduled"
+	RunStatusClaimed       RunStatus = "claimed"
+	RunStatusRunning       RunStatus = "running"
+	RunStatusSucceeded     RunStatus = "succeeded"
+	RunStatusFailed        RunStatus = "failed"
+	RunStatusCancelled     RunStatus = "cancelled"
+	RunStatusTimedOut      RunStatus = "timed_out"
+	RunStatusSkipped       RunStatus = "skipped"        // Slot skipped because an earlier run was still active
+	RunStatusInterrupted   RunStatus = "interrupted"    // Stopped because its worker shut down
+	RunStatusLimitExceeded RunStatus = "limit_exceeded" // Ended by going over one of its resource limits
+)
+
+// RunTrigger records what created a run
+type RunTrigger string
+
+const (
+	RunTriggerSchedule RunTrigger = "schedule" // Created by the scheduler for a cron slot
+	RunTriggerBackfill RunTrigger = "backfill" // Created by a backfill over past slots
+	RunTriggerManual   RunTrigger = "manual"   // Created on demand through the API
 )
 
+// RunOverrides replaces parts of a job's configuration for a single run
+type RunOverrides struct {
+	Args    []string          `json:"args,omitempty"`    // Replaces the job's args
+	Env     map[string]string `json:"env,omitempty"`     // Merged over the job's env
+	Timeout *Duration         `json:"timeout,omitempty"` // Replaces the job's timeout
+}
+
+// RunOutput is what a finished run's process wrote and how it exited
+type RunOutput struct {
+	Output    string // Stdout and stderr interleaved
+	Stdout    string
+	Stderr    string
+	ExitCode  *int
+	Signal    *string // Signal that ended the process
+	Truncated bool
+	Result    json.RawMessage // JSON object the job reported, nil for none
+}
+
 // Run represents a single execution of a Job
 type Run struct {
-	ID          uuid.UUID  `json:"id" db:"id"`
-	JobID       uuid.UUID  `json:"job_id" db:"job_id"`
-	Status      RunStatus  `json:"status" db:"status"`
-	AttemptNum  int        `json:"attempt_num" db:"attempt_num"`
-	ScheduledAt time.Time  `json:"scheduled_at" db:"scheduled_at"`
-	StartedAt   *time.Time `json:"started_at,omitempty" db:"started_at"`
-	FinishedAt  *time.Time `json:"finished_at,omitempty" db:"finished_at"`
-	Output      string     `json:"output" db:"output"`
-	ErrorMsg    *string    `json:"error_msg,omitempty" db:"error_msg"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ID                uuid.UUID       `json:"id" db:"id"`
+	JobID             uuid.UUID       `json:"job_id" db:"job_id"`
+	Status            RunStatus       `json:"status" db:"status"`
+	AttemptNum        int             `json:"attempt_num" db:"attempt_num"`
+	RetryOf           *uuid.UUID      `json:"retry_of,omitempty" db:"retry_of"`
+	Trigger           RunTrigger      `json:"trigger" db:"trigger"`
+	BackfillID        *uuid.UUID      `json:"backfill_id,omitempty" db:"backfill_id"`
+	TriggeredBy       *string         `json:"triggered_by,omitempty" db:"triggered_by"`
+	Overrides         *RunOverrides   `json:"overrides,omitempty" db:"overrides"`
+	ScheduledAt       time.Time       `json:"scheduled_at" db:"scheduled_at"`
+	StartedAt         *time.Time      `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt        *time.Time      `json:"finished_at,omitempty" db:"finished_at"`
+	Output            string          `json:"output" db:"output"`
+	Stdout            string          `json:"stdout" db:"stdout"`
+	Stderr            string          `json:"stderr" db:"stderr"`
+	ExitCode          *int            `json:"exit_code,omitempty" db:"exit_code"`
+	TermSignal        *string         `json:"term_signal,omitempty" db:"term_signal"`
+	OutputTruncated   bool            `json:"output_truncated" db:"output_truncated"`
+	Result            json.RawMessage `json:"result,omitempty" db:"result"`
+	ErrorMsg          *string         `json:"error_msg,omitempty" db:"error_msg"`
+	ClaimedBy         *string         `json:"claimed_by,omitempty" db:"claimed_by"`
+	ClaimedAt         *time.Time      `json:"claimed_at,omitempty" db:"claimed_at"`
+	HeartbeatAt       *time.Time      `json:"heartbeat_at,omitempty" db:"heartbeat_at"`
+	CancelRequestedAt *time.Time      `json:"cancel_requested_at,omitempty" db:"cancel_requested_at"`
+	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,12 @@
+package types
+
+import "time"
+
+// Lease is a time-limited claim on a named role, such as scheduler leadership
+type Lease struct {
+	Name       string    `json:"name" db:"name"`
+	Holder     string    `json:"holder" db:"holder"`
+	AcquiredAt time.Time `json:"acquired_at" db:"acquired_at"`
+	RenewedAt  time.Time `json:"renewed_at" db:"renewed_at"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Output streams of a run's process
+const (
+	LogStreamStdout = "stdout"
+	LogStreamStderr = "stderr"
+)
+
+// RunLog is a chunk of a run's output, stored while the run is in flight
+type RunLog struct {
+	ID        int64     `json:"id" db:"id"`
+	RunID     uuid.UUID `json:"run_id" db:"run_id"`
+	Stream    string    `json:"stream" db:"stream"`
+	Data      string    `json:"data" db:"data"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
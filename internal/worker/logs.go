@@ -0,0 +1,186 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Franklyne-kibet/aster-scheduler/internal/db/store"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
+)
+
+const (
+	// logFlushInterval is how often buffered output is written to run_logs
+	logFlushInterval = 500 * time.Millisecond
+
+	// logFlushBytes triggers an early flush once this much output is buffered
+	logFlushBytes = 64 << 10
+
+	// maxPendingLogBytes bounds the buffer while the database is slow; output
+	// beyond it is dropped from the live logs
+	maxPendingLogBytes = 1 << 20
+
+	// maxRunLogBytes bounds how much output a single run stores as live logs
+	maxRunLogBytes = 16 << 20
+)
+
+// logChunk is output waiting to be written to run_logs
+type logChunk struct {
+	stream string
+	data   []byte
+}
+
+// runLogWriter batches a run's output and stores it in run_logs in the
+// background, so it can be followed while the run is in flight. Storing
+// logs is best effort; the run's output summary is recorded either way.
+type runLogWriter struct {
+	store  *store.RunLogStore
+	runID  uuid.UUID
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	pending []logChunk
+	size    int
+	written int64
+	dropped int64
+	partial map[string][]byte // Trailing bytes of an unfinished UTF-8 character per stream
+
+	flush chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// newRunLogWriter creates a log writer for a run and starts flushing it.
+// Close must be called once the run's process has exited.
+func newRunLogWriter(ctx context.Context, logStore *store.RunLogStore, runID uuid.UUID, logger *zap.Logger) *runLogWriter {
+	l := &runLogWriter{
+		store:   logStore,
+		runID:   runID,
+		logger:  logger,
+		partial: make(map[string][]byte),
+		flush:   make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go l.run(ctx)
+	return l
+}
+
+// WriteLog buffers a chunk of output
+func (l *runLogWriter) WriteLog(stream string, data []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.size+len(data) > maxPendingLogBytes || l.written+int64(len(data)) > maxRunLogBytes {
+		l.dropped += int64(len(data))
+		return
+	}
+
+	// Consecutive writes to the same stream become one row
+	if n := len(l.pending); n > 0 && l.pending[n-1].stream == stream {
+		l.pending[n-1].data = append(l.pending[n-1].data, data...)
+	} else {
+		l.pending = append(l.pending, logChunk{stream: stream, data: append([]byte(nil), data...)})
+	}
+	l.size += len(data)
+	l.written += int64(len(data))
+
+	if l.size >= logFlushBytes {
+		select {
+		case l.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close stores any output still buffered and stops the writer
+func (l *runLogWriter) Close() {
+	close(l.stop)
+	<-l.done
+}
+
+// run flushes buffered output until the writer is closed
+func (l *runLogWriter) run(ctx context.Context) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(logFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.write(ctx, false)
+		case <-l.flush:
+			l.write(ctx, false)
+		case <-l.stop:
+			l.write(ctx, true)
+			return
+		}
+	}
+}
+
+// write stores the buffered output. Unless final, a character split across
+// writes is held back until the rest of it arrives.
+func (l *runLogWriter) write(ctx context.Context, final bool) {
+	l.mu.Lock()
+	pending := l.pending
+	l.pending = nil
+	l.size = 0
+
+	logs := make([]types.RunLog, 0, len(pending)+1)
+	for _, chunk := range pending {
+		data := append(l.partial[chunk.stream], chunk.data...)
+		l.partial[chunk.stream] = nil
+		if !final {
+			cut := incompleteSuffix(data)
+			l.partial[chunk.stream] = append([]byte(nil), data[len(data)-cut:]...)
+			data = data[:len(data)-cut]
+		}
+		if len(data) > 0 {
+			logs = append(logs, types.RunLog{Stream: chunk.stream, Data: string(data)})
+		}
+	}
+
+	if final {
+		for stream, data := range l.partial {
+			if len(data) > 0 {
+				logs = append(logs, types.RunLog{Stream: stream, Data: string(data)})
+			}
+		}
+		if l.dropped > 0 {
+			logs = append(logs, types.RunLog{
+				Stream: types.LogStreamStderr,
+				Data:   fmt.Sprintf("\n... [%d bytes of output not stored in the live log] ...\n", l.dropped),
+			})
+		}
+	}
+	l.mu.Unlock()
+
+	if err := l.store.AppendRunLogs(ctx, l.runID, logs); err != nil {
+		l.logger.Warn("Failed to store run logs",
+			zap.String("run_id", l.runID.String()),
+			zap.Int("chunks", len(logs)),
+			zap.Error(err))
+	}
+}
+
+// incompleteSuffix returns how many trailing bytes of data belong to a UTF-8
+// character that hasn't been fully written yet
+func incompleteSuffix(data []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		start := len(data) - i
+		if utf8.RuneStart(data[start]) {
+			if utf8.FullRune(data[start:]) {
+				return 0
+			}
+			return i
+		}
+	}
+	return 0
+}
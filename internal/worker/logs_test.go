@@ -0,0 +1,28 @@
+package worker
+
+import "testing"
+
+func TestIncompleteSuffix(t *testing.T) {
+	euro := []byte("€") // 3 bytes
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"empty", nil, 0},
+		{"ascii", []byte("abc"), 0},
+		{"complete character", append([]byte("a"), euro...), 0},
+		{"one byte of three", append([]byte("a"), euro[:1]...), 1},
+		{"two bytes of three", append([]byte("a"), euro[:2]...), 2},
+		{"invalid byte", []byte("a\xff"), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := incompleteSuffix(tt.data); got != tt.want {
+				t.Errorf("incompleteSuffix(%q) = %d, want %d", tt.data, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package worker
+
+import "sync"
+
+// pool bounds how many runs a worker executes at once. Slots are reserved
+// before runs are claimed so a worker never claims more than it can start.
+type pool struct {
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+// newPool creates a pool with the given number of slots (at least one)
+func newPool(size int) *pool {
+	if size < 1 {
+		size = 1
+	}
+	return &pool{slots: make(chan struct{}, size)}
+}
+
+// size returns the total number of slots
+func (p *pool) size() int {
+	return cap(p.slots)
+}
+
+// inUse returns the number of slots currently reserved or running
+func (p *pool) inUse() int {
+	return len(p.slots)
+}
+
+// reserve takes up to n free slots without blocking and returns how many it got
+func (p *pool) reserve(n int) int {
+	reserved := 0
+	for reserved < n {
+		select {
+		case p.slots <- struct{}{}:
+			reserved++
+		default:
+			return reserved
+		}
+	}
+	return reserved
+}
+
+// release frees a reserved slot
+func (p *pool) release() {
+	<-p.slots
+}
+
+// spawn runs fn on a slot previously taken with reserve and frees it when fn returns
+func (p *pool) spawn(fn func()) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer p.release()
+		fn()
+	}()
+}
+
+// wait blocks until every spawned function has returned
+func (p *pool) wait() {
+	p.wg.Wait()
+}
@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_NeverExceedsSize(t *testing.T) {
+	const size = 3
+	const tasks = 20
+
+	p := newPool(size)
+
+	var running, maxRunning, completed atomic.Int32
+
+	// Keep reserving slots like the worker's poll loop until every task has run
+	started := 0
+	for started < tasks {
+		n := p.reserve(tasks - started)
+		for range n {
+			started++
+			p.spawn(func() {
+				now := running.Add(1)
+				for {
+					prev := maxRunning.Load()
+					if now <= prev || maxRunning.CompareAndSwap(prev, now) {
+						break
+					}
+				}
+
+				if p.inUse() > size {
+					t.Errorf("Pool reports %d slots in use, limit is %d", p.inUse(), size)
+				}
+
+				time.Sleep(5 * time.Millisecond)
+				running.Add(-1)
+				completed.Add(1)
+			})
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	p.wait()
+
+	if got := completed.Load(); got != tasks {
+		t.Errorf("Expected %d completed tasks, got %d", tasks, got)
+	}
+
+	if got := maxRunning.Load(); got > size {
+		t.Errorf("Expected at most %d concurrent tasks, saw %d", size, got)
+	}
+
+	if got := maxRunning.Load(); got < 2 {
+		t.Errorf("Expected tasks to run concurrently, saw at most %d", got)
+	}
+
+	if p.inUse() != 0 {
+		t.Errorf("Expected all slots to be free, %d still in use", p.inUse())
+	}
+}
+
+func TestPool_Reserve(t *testing.T) {
+	p := newPool(2)
+
+	if got := p.reserve(5); got != 2 {
+		t.Errorf("Expected to reserve 2 slots, got %d", got)
+	}
+
+	if got := p.reserve(1); got != 0 {
+		t.Errorf("Expected no free slots, reserved %d", got)
+	}
+
+	if p.inUse() != 2 {
+		t.Errorf("Expected 2 slots in use, got %d", p.inUse())
+	}
+
+	p.release()
+
+	if got := p.reserve(1); got != 1 {
+		t.Errorf("Expected to reserve the released slot, got %d", got)
+	}
+}
+
+func TestNewPool_MinimumSize(t *testing.T) {
+	if got := newPool(0).size(); got != 1 {
+		t.Errorf("Expected pool size 1 for non-positive size, got %d", got)
+	}
+}
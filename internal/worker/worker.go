@@ -2,39 +2,72 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/Franklyne-kibet/aster-scheduler/internal/db/store"
 	"github.com/Franklyne-kibet/aster-scheduler/internal/executor"
+	"github.com/Franklyne-kibet/aster-scheduler/internal/scheduler"
 	"github.com/Franklyne-kibet/aster-scheduler/internal/types"
 )
 
+// errCancelRequested is the cause attached to runs stopped because their
+// cancel_requested_at flag was set
+var errCancelRequested = errors.New("cancellation requested")
+
+// errRunLost is the cause attached to runs this worker no longer owns, for
+// example because the scheduler reaped them after missed heartbeats
+var errRunLost = errors.New("run no longer owned by this worker")
+
+// errShuttingDown is the cause attached to runs stopped because the worker
+// shut down before they finished
+var errShuttingDown = errors.New("worker shut down before the run finished")
+
 // Worker polls for scheduled runs and executes them
 type Worker struct {
-	id       string // Unique worker identifier
-	jobStore *store.JobStore
-	runStore *store.RunStore
-	executor *executor.Executor
-	logger   *zap.Logger
+	id          string // Unique worker identifier
+	jobStore    *store.JobStore
+	runStore    *store.RunStore
+	runLogStore *store.RunLogStore // Receives live output when set
+	executor    executor.Executor
+	logger      *zap.Logger
 
 	// Configuration
-	pollInterval time.Duration
-	maxJobs      int // Maximum concurrent jobs
+	pollInterval      time.Duration
+	heartbeatInterval time.Duration // How often in-flight runs are heartbeated
+	shutdownGrace     time.Duration // How long in-flight runs may finish after Run's context ends
+	pool              *pool         // Bounds concurrent runs
+
+	mu      sync.Mutex
+	running map[uuid.UUID]*trackedRun // Runs executing on this worker
+}
+
+// trackedRun is a run executing on this worker. A run asked to stop stays
+// tracked, and heartbeated, until its process has exited, so it isn't
+// reaped while it is still shutting down.
+type trackedRun struct {
+	cancel   context.CancelCauseFunc
+	stopping bool
 }
 
 // NewWorker creates a new worker instance
-func NewWorker(id string, jobStore *store.JobStore, runStore *store.RunStore, executor *executor.Executor, logger *zap.Logger) *Worker {
+func NewWorker(id string, jobStore *store.JobStore, runStore *store.RunStore, executor executor.Executor, logger *zap.Logger) *Worker {
 	return &Worker{
-		id:           id,
-		jobStore:     jobStore,
-		runStore:     runStore,
-		executor:     executor,
-		logger:       logger,
-		pollInterval: 5 * time.Second, // Poll every 5 seconds
-		maxJobs:      1,               // Simple worker - one job at a time
+		id:                id,
+		jobStore:          jobStore,
+		runStore:          runStore,
+		executor:          executor,
+		logger:            logger,
+		pollInterval:      5 * time.Second,  // Poll every 5 seconds
+		heartbeatInterval: 10 * time.Second, // Well inside the scheduler's heartbeat timeout
+		shutdownGrace:     30 * time.Second, // Matches the default SHUTDOWN_GRACE_PERIOD
+		pool:              newPool(1),       // One run at a time unless configured
+		running:           make(map[uuid.UUID]*trackedRun),
 	}
 }
 
@@ -43,29 +76,84 @@ func (w *Worker) SetPollInterval(interval time.Duration) {
 	w.pollInterval = interval
 }
 
-// Run starts the worker (blocking operation)
+// SetHeartbeatInterval configures how often in-flight runs are heartbeated.
+// It must stay well below the scheduler's run heartbeat timeout.
+func (w *Worker) SetHeartbeatInterval(interval time.Duration) {
+	w.heartbeatInterval = interval
+}
+
+// SetShutdownGracePeriod configures how long in-flight runs may keep going
+// once the worker is asked to stop
+func (w *Worker) SetShutdownGracePeriod(grace time.Duration) {
+	w.shutdownGrace = grace
+}
+
+// SetRunLogStore makes the worker store runs' output in run_logs while
+// they execute, so it can be followed live
+func (w *Worker) SetRunLogStore(runLogStore *store.RunLogStore) {
+	w.runLogStore = runLogStore
+}
+
+// SetPoolSize configures how many runs may execute at once.
+// It must be called before Run.
+func (w *Worker) SetPoolSize(size int) {
+	w.pool = newPool(size)
+}
+
+// PoolSize returns the maximum number of concurrent runs
+func (w *Worker) PoolSize() int {
+	return w.pool.size()
+}
+
+// SlotsInUse returns how many pool slots are currently executing runs
+func (w *Worker) SlotsInUse() int {
+	return w.pool.inUse()
+}
+
+// Run starts the worker (blocking operation). When ctx is cancelled the
+// worker stops claiming runs and drains the ones in flight before returning.
 func (w *Worker) Run(ctx context.Context) error {
 	w.logger.Info("Starting worker",
 		zap.String("worker_id", w.id),
 		zap.Duration("poll_interval", w.pollInterval),
-		zap.Int("max_concurrent_jobs", w.maxJobs))
+		zap.Int("max_concurrent_jobs", w.pool.size()))
+
+	// Runs execute under their own context so that stopping the worker lets
+	// them finish instead of killing them straight away
+	execCtx, stopRuns := context.WithCancelCause(context.WithoutCancel(ctx))
+	defer stopRuns(nil)
 
 	ticker := time.NewTicker(w.pollInterval)
 	defer ticker.Stop()
 
+	heartbeatTicker := time.NewTicker(w.heartbeatInterval)
+	defer heartbeatTicker.Stop()
+
 	// Do an initial check immediately
-	if err := w.checkAndExecuteRuns(ctx); err != nil {
+	if err := w.checkAndExecuteRuns(execCtx); err != nil {
 		w.logger.Error("Error in initial run check", zap.Error(err))
 	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			w.logger.Info("Worker stopping due to context cancellation")
+			w.logger.Info("Worker draining, no longer claiming runs",
+				zap.Int("runs_in_flight", w.pool.inUse()),
+				zap.Duration("shutdown_grace_period", w.shutdownGrace))
+			w.drain(execCtx, stopRuns)
 			return ctx.Err()
 
+		case <-heartbeatTicker.C:
+			if err := w.heartbeat(execCtx); err != nil {
+				w.logger.Error("Error sending run heartbeats", zap.Error(err))
+			}
+
 		case <-ticker.C:
-			if err := w.checkAndExecuteRuns(ctx); err != nil {
+			if err := w.checkCancellations(execCtx); err != nil {
+				w.logger.Error("Error checking for cancelled runs", zap.Error(err))
+			}
+
+			if err := w.checkAndExecuteRuns(execCtx); err != nil {
 				w.logger.Error("Error checking for runs", zap.Error(err))
 				// Don't stop worker on errors
 			}
@@ -73,38 +161,112 @@ func (w *Worker) Run(ctx context.Context) error {
 	}
 }
 
-// checkAndExecuteRuns looks for scheduled runs and executes them
+// drain waits for in-flight runs to finish, still heartbeating them and
+// honouring cancel requests. Runs going past the shutdown grace period are
+// stopped through stopRuns and recorded as interrupted.
+func (w *Worker) drain(execCtx context.Context, stopRuns context.CancelCauseFunc) {
+	// Keep talking to the database after the runs have been stopped
+	ctx := context.WithoutCancel(execCtx)
+
+	done := make(chan struct{})
+	go func() {
+		w.pool.wait()
+		close(done)
+	}()
+
+	grace := time.NewTimer(w.shutdownGrace)
+	defer grace.Stop()
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	heartbeatTicker := time.NewTicker(w.heartbeatInterval)
+	defer heartbeatTicker.Stop()
+
+	for {
+		select {
+		case <-done:
+			w.logger.Info("Worker drained")
+			return
+
+		case <-grace.C:
+			w.logger.Warn("Shutdown grace period over, stopping remaining runs",
+				zap.Int("runs_in_flight", w.pool.inUse()))
+			stopRuns(errShuttingDown)
+
+		case <-heartbeatTicker.C:
+			if err := w.heartbeat(ctx); err != nil {
+				w.logger.Error("Error sending run heartbeats", zap.Error(err))
+			}
+
+		case <-ticker.C:
+			if err := w.checkCancellations(ctx); err != nil {
+				w.logger.Error("Error checking for cancelled runs", zap.Error(err))
+			}
+		}
+	}
+}
+
+// checkAndExecuteRuns claims as many scheduled runs as there are free pool
+// slots and starts executing them in the background
 func (w *Worker) checkAndExecuteRuns(ctx context.Context) error {
-	// Get scheduled runs (limit to maxJobs for simplicity)
-	runs, err := w.runStore.GetRunsByStatus(ctx, types.RunStatusScheduled, w.maxJobs)
+	// Reserve slots first so we never claim runs we can't start
+	free := w.pool.reserve(w.pool.size())
+	if free == 0 {
+		w.logger.Debug("No free slots, skipping claim",
+			zap.Int("slots_in_use", w.pool.inUse()))
+		return nil
+	}
+
+	// Claim runs atomically so other workers can't pick up the same ones
+	runs, err := w.runStore.ClaimRuns(ctx, w.id, free)
 	if err != nil {
-		return fmt.Errorf("failed to get scheduled runs: %w", err)
+		w.releaseSlots(free)
+		return fmt.Errorf("failed to claim scheduled runs: %w", err)
 	}
 
+	// Give back the slots we couldn't fill
+	w.releaseSlots(free - len(runs))
+
 	if len(runs) == 0 {
 		w.logger.Debug("No scheduled runs found")
 		return nil
 	}
 
-	w.logger.Info("Found scheduled runs", zap.Int("count", len(runs)))
+	w.logger.Info("Claimed scheduled runs",
+		zap.String("worker_id", w.id),
+		zap.Int("count", len(runs)),
+		zap.Int("slots_in_use", w.pool.inUse()),
+		zap.Int("pool_size", w.pool.size()))
 
 	for _, run := range runs {
-		if err := w.executeRun(ctx, run); err != nil {
-			w.logger.Error("Failed to execute run",
-				zap.String("run_id", run.ID.String()),
-				zap.String("job_id", run.JobID.String()),
-				zap.Error(err))
-			// Continue with other runs
-		}
+		w.pool.spawn(func() {
+			if err := w.executeRun(ctx, run); err != nil {
+				w.logger.Error("Failed to execute run",
+					zap.String("run_id", run.ID.String()),
+					zap.String("job_id", run.JobID.String()),
+					zap.Error(err))
+			}
+		})
 	}
 
 	return nil
 }
 
-// executeRun executes a single run
+// releaseSlots returns n reserved slots to the pool
+func (w *Worker) releaseSlots(n int) {
+	for range n {
+		w.pool.release()
+	}
+}
+
+// executeRun executes a single run. Cancelling ctx stops the run; its
+// bookkeeping is still written afterwards.
 func (w *Worker) executeRun(ctx context.Context, run *types.Run) error {
+	dbCtx := context.WithoutCancel(ctx)
+
 	// First, get the job details
-	job, err := w.jobStore.GetJob(ctx, run.JobID)
+	job, err := w.jobStore.GetJob(dbCtx, run.JobID)
 	if err != nil {
 		return fmt.Errorf("failed to get job for run: %w", err)
 	}
@@ -114,13 +276,69 @@ func (w *Worker) executeRun(ctx context.Context, run *types.Run) error {
 		zap.String("job_id", job.ID.String()),
 		zap.String("job_name", job.Name))
 
+	// Track the run so a cancel request can stop it
+	runCtx, cancel := context.WithCancelCause(ctx)
+	w.trackRun(run.ID, cancel)
+	defer w.untrackRun(run.ID)
+
+	// Hand the run back if the worker is already stopping its runs
+	if ctx.Err() != nil {
+		if err := w.runStore.ReleaseRun(dbCtx, run.ID); err != nil {
+			return fmt.Errorf("failed to release run: %w", err)
+		}
+
+		w.logger.Info("Released run back to the queue",
+			zap.String("run_id", run.ID.String()),
+			zap.String("job_name", job.Name))
+		return nil
+	}
+
 	// Mark run as started
-	if err := w.runStore.MarkRunStarted(ctx, run.ID); err != nil {
+	started, err := w.runStore.MarkRunStarted(dbCtx, run.ID)
+	if err != nil {
 		return fmt.Errorf("failed to mark run as started: %w", err)
 	}
+	if !started {
+		// Cancelled between being claimed and starting
+		errStr := errCancelRequested.Error()
+		if err := w.runStore.MarkRunFinished(dbCtx, run.ID, types.RunStatusCancelled, types.RunOutput{}, &errStr); err != nil {
+			return fmt.Errorf("failed to mark run as cancelled: %w", err)
+		}
+
+		w.logger.Info("Run cancelled before it started",
+			zap.String("run_id", run.ID.String()),
+			zap.String("job_name", job.Name))
+		return nil
+	}
 
-	// Execute the job
-	result := w.executor.Execute(ctx, job)
+	// Stream output to run_logs while the run executes
+	execCtx := runCtx
+	var logs *runLogWriter
+	if w.runLogStore != nil {
+		logs = newRunLogWriter(dbCtx, w.runLogStore, run.ID, w.logger)
+		execCtx = executor.WithLogWriter(runCtx, logs)
+	}
+
+	// Execute the job with any per-run overrides applied. Logs are complete
+	// before the run is marked finished, so followers see all of them.
+	result := w.executor.Execute(execCtx, executor.ApplyOverrides(job, run.Overrides), run)
+	if logs != nil {
+		logs.Close()
+	}
+	switch context.Cause(runCtx) {
+	case errCancelRequested:
+		result.Status = types.RunStatusCancelled
+		result.Error = errCancelRequested
+	case errShuttingDown:
+		result.Status = types.RunStatusInterrupted
+		result.Error = errShuttingDown
+	case errRunLost:
+		// The scheduler already failed the run and queued any retry
+		w.logger.Warn("Abandoned run no longer owned by this worker",
+			zap.String("run_id", run.ID.String()),
+			zap.String("job_name", job.Name))
+		return nil
+	}
 
 	// Prepare error message for database
 	var errorMsg *string
@@ -130,11 +348,30 @@ func (w *Worker) executeRun(ctx context.Context, run *types.Run) error {
 	}
 
 	// Mark run as finished with results
-	if err := w.runStore.MarkRunFinished(ctx, run.ID, result.Status, result.Output, errorMsg); err != nil {
+	finished := true
+	output := types.RunOutput{
+		Output:    result.Output,
+		Stdout:    result.Stdout,
+		Stderr:    result.Stderr,
+		ExitCode:  result.ExitCode,
+		Truncated: result.OutputTruncated,
+		Result:    result.Result,
+	}
+	if result.Signal != "" {
+		output.Signal = &result.Signal
+	}
+	if err := w.runStore.MarkRunFinished(dbCtx, run.ID, result.Status, output, errorMsg); err != nil {
 		w.logger.Error("Failed to mark run as finished",
 			zap.String("run_id", run.ID.String()),
 			zap.Error(err))
 		// This is a problem but don't fail the execution
+		finished = false
+	}
+
+	// Queue a retry if the job still has attempts left. If the result wasn't
+	// recorded the run may have been reaped, which already retried it.
+	if finished && scheduler.IsRetryable(result.Status) {
+		w.scheduleRetry(dbCtx, job, run)
 	}
 
 	// Log execution summary
@@ -146,3 +383,129 @@ func (w *Worker) executeRun(ctx context.Context, run *types.Run) error {
 
 	return nil
 }
+
+// trackRun records the cancel function of a run executing on this worker
+func (w *Worker) trackRun(runID uuid.UUID, cancel context.CancelCauseFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.running[runID] = &trackedRun{cancel: cancel}
+}
+
+// untrackRun forgets a finished run and releases its context
+func (w *Worker) untrackRun(runID uuid.UUID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if tracked, ok := w.running[runID]; ok {
+		tracked.cancel(nil)
+		delete(w.running, runID)
+	}
+}
+
+// stopRun asks a run to stop with cause, unless it already has been. It
+// reports whether the run was asked. The caller must hold w.mu.
+func (w *Worker) stopRun(runID uuid.UUID, cause error) bool {
+	tracked, ok := w.running[runID]
+	if !ok || tracked.stopping {
+		return false
+	}
+	tracked.stopping = true
+	tracked.cancel(cause)
+	return true
+}
+
+// runningIDs returns the runs currently executing on this worker
+func (w *Worker) runningIDs() []uuid.UUID {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	runIDs := make([]uuid.UUID, 0, len(w.running))
+	for runID := range w.running {
+		runIDs = append(runIDs, runID)
+	}
+	return runIDs
+}
+
+// heartbeat tells the scheduler this worker is still executing its runs, and
+// abandons runs it turns out to no longer own
+func (w *Worker) heartbeat(ctx context.Context) error {
+	runIDs := w.runningIDs()
+	if len(runIDs) == 0 {
+		return nil
+	}
+
+	owned, err := w.runStore.HeartbeatRuns(ctx, w.id, runIDs)
+	if err != nil {
+		return err
+	}
+
+	stillOwned := make(map[uuid.UUID]bool, len(owned))
+	for _, runID := range owned {
+		stillOwned[runID] = true
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, runID := range runIDs {
+		if stillOwned[runID] {
+			continue
+		}
+		if w.stopRun(runID, errRunLost) {
+			w.logger.Warn("Stopping run no longer owned by this worker", zap.String("run_id", runID.String()))
+		}
+	}
+
+	return nil
+}
+
+// checkCancellations stops runs on this worker that have been flagged for
+// cancellation, for example because a newer run of a replace job started
+func (w *Worker) checkCancellations(ctx context.Context) error {
+	runIDs := w.runningIDs()
+	if len(runIDs) == 0 {
+		return nil
+	}
+
+	cancelled, err := w.runStore.GetCancelRequested(ctx, runIDs)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, runID := range cancelled {
+		if w.stopRun(runID, errCancelRequested) {
+			w.logger.Info("Cancelling run", zap.String("run_id", runID.String()))
+		}
+	}
+
+	return nil
+}
+
+// scheduleRetry creates the next attempt for a failed run, honoring the job's
+// retry limit and backoff policy
+func (w *Worker) scheduleRetry(ctx context.Context, job *types.Job, run *types.Run) {
+	retry := scheduler.NewRetryRun(job, run, time.Now())
+	if retry == nil {
+		w.logger.Info("Run failed with no retries left",
+			zap.String("run_id", run.ID.String()),
+			zap.String("job_name", job.Name),
+			zap.Int("attempt_num", run.AttemptNum),
+			zap.Int("max_retries", job.MaxRetries))
+		return
+	}
+
+	if err := w.runStore.CreateRun(ctx, retry); err != nil {
+		w.logger.Error("Failed to schedule retry",
+			zap.String("run_id", run.ID.String()),
+			zap.String("job_name", job.Name),
+			zap.Error(err))
+		return
+	}
+
+	w.logger.Info("Scheduled retry",
+		zap.String("run_id", retry.ID.String()),
+		zap.String("retry_of", run.ID.String()),
+		zap.String("job_name", job.Name),
+		zap.Int("attempt_num", retry.AttemptNum),
+		zap.Time("scheduled_at", retry.ScheduledAt))
+}
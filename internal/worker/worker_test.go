@@ -37,11 +37,12 @@ func setupWorkerTest(t *testing.T) (*Worker, *store.JobStore, *store.RunStore) {
 	logger := zaptest.NewLogger(t)
 	jobStore := store.NewJobStore(database.Pool())
 	runStore := store.NewRunStore(database.Pool())
-	executor := executor.NewExecutor(logger)
+	executor := executor.NewRegistry(logger, executor.Settings{})
 	worker := NewWorker("test-worker-1", jobStore, runStore, executor, logger)
 
 	// Speed up polling for tests
 	worker.SetPollInterval(100 * time.Millisecond)
+	worker.SetPoolSize(5)
 
 	return worker, jobStore, runStore
 }
@@ -146,13 +147,14 @@ func TestWorker_CheckAndExecuteRuns(t *testing.T) {
 		}
 	}
 
-	// Execute all scheduled runs
+	// Execute all scheduled runs and wait for the pool to drain
 	if err := worker.checkAndExecuteRuns(ctx); err != nil {
 		t.Fatalf("Failed to check and execute runs: %v", err)
 	}
+	worker.pool.wait()
 
 	// Verify all runs completed
-	runs, err := runStore.ListRuns(ctx, nil, 10, 0)
+	runs, err := runStore.ListRuns(ctx, store.RunFilter{}, 10, 0)
 	if err != nil {
 		t.Fatalf("Failed to list runs: %v", err)
 	}
@@ -169,4 +171,247 @@ func TestWorker_CheckAndExecuteRuns(t *testing.T) {
 	if completedCount < 3 {
 		t.Errorf("Expected at least 3 completed runs, got %d", completedCount)
 	}
+
+	// Every executed run should record the worker that claimed it
+	for _, run := range runs {
+		if run.Status != types.RunStatusSucceeded {
+			continue
+		}
+		if run.ClaimedBy == nil || *run.ClaimedBy != "test-worker-1" {
+			t.Errorf("Expected run %s to be claimed by test-worker-1, got %v", run.ID, run.ClaimedBy)
+		}
+	}
+}
+
+func TestWorker_CancelRequestedRun(t *testing.T) {
+	worker, jobStore, runStore := setupWorkerTest(t)
+	if worker == nil {
+		return
+	}
+
+	ctx := context.Background()
+
+	job := &types.Job{
+		ID:                uuid.New(),
+		Name:              "test_worker_replace",
+		Command:           "sleep",
+		Args:              []string{"30"},
+		Status:            types.JobStatusActive,
+		ConcurrencyPolicy: types.ConcurrencyReplace,
+	}
+	if err := jobStore.CreateJob(ctx, job); err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+
+	run := &types.Run{
+		ID:          uuid.New(),
+		JobID:       job.ID,
+		Status:      types.RunStatusScheduled,
+		AttemptNum:  1,
+		ScheduledAt: time.Now(),
+	}
+	if err := runStore.CreateRun(ctx, run); err != nil {
+		t.Fatalf("Failed to create run: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- worker.executeRun(ctx, run) }()
+
+	// Wait for the run to start before flagging it
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		current, err := runStore.GetRun(ctx, run.ID)
+		if err != nil {
+			t.Fatalf("Failed to get run: %v", err)
+		}
+		if current.Status == types.RunStatusRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Run never started")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if n, err := runStore.RequestCancelActiveRuns(ctx, job.ID); err != nil || n != 1 {
+		t.Fatalf("Expected to flag 1 run, got %d, %v", n, err)
+	}
+	if err := worker.checkCancellations(ctx); err != nil {
+		t.Fatalf("Failed to check cancellations: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Failed to execute run: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run was not stopped after cancellation was requested")
+	}
+
+	updated, err := runStore.GetRun(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("Failed to get run: %v", err)
+	}
+	if updated.Status != types.RunStatusCancelled {
+		t.Errorf("Expected status %s, got %s", types.RunStatusCancelled, updated.Status)
+	}
+}
+
+func TestWorker_CancelledBeforeStart(t *testing.T) {
+	worker, jobStore, runStore := setupWorkerTest(t)
+	if worker == nil {
+		return
+	}
+
+	ctx := context.Background()
+
+	job := &types.Job{
+		ID:      uuid.New(),
+		Name:    "test_worker_cancel_claimed",
+		Command: "echo",
+		Args:    []string{"should not run"},
+		Status:  types.JobStatusActive,
+	}
+	if err := jobStore.CreateJob(ctx, job); err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+
+	run := &types.Run{
+		ID:          uuid.New(),
+		JobID:       job.ID,
+		Status:      types.RunStatusScheduled,
+		AttemptNum:  1,
+		ScheduledAt: time.Now(),
+	}
+	if err := runStore.CreateRun(ctx, run); err != nil {
+		t.Fatalf("Failed to create run: %v", err)
+	}
+
+	// Claim the run, then cancel it before the worker starts it
+	claimed, err := runStore.ClaimRuns(ctx, "test-worker-1", 1)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("Expected to claim 1 run, got %d, %v", len(claimed), err)
+	}
+	if _, err := runStore.CancelRun(ctx, run.ID); err != nil {
+		t.Fatalf("Failed to cancel run: %v", err)
+	}
+
+	if err := worker.executeRun(ctx, claimed[0]); err != nil {
+		t.Fatalf("Failed to execute run: %v", err)
+	}
+
+	updated, err := runStore.GetRun(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("Failed to get run: %v", err)
+	}
+	if updated.Status != types.RunStatusCancelled {
+		t.Errorf("Expected status %s, got %s", types.RunStatusCancelled, updated.Status)
+	}
+	if updated.StartedAt != nil || updated.Output != "" {
+		t.Errorf("Expected the run never to start, got started_at %v output %q", updated.StartedAt, updated.Output)
+	}
+}
+
+func TestWorker_DrainOnShutdown(t *testing.T) {
+	worker, jobStore, runStore := setupWorkerTest(t)
+	if worker == nil {
+		return
+	}
+
+	ctx := context.Background()
+
+	createRun := func(name, sleep string) *types.Run {
+		job := &types.Job{
+			ID:      uuid.New(),
+			Name:    name,
+			Command: "sleep",
+			Args:    []string{sleep},
+			Status:  types.JobStatusActive,
+		}
+		if err := jobStore.CreateJob(ctx, job); err != nil {
+			t.Fatalf("Failed to create job: %v", err)
+		}
+
+		run := &types.Run{
+			ID:          uuid.New(),
+			JobID:       job.ID,
+			Status:      types.RunStatusScheduled,
+			AttemptNum:  1,
+			ScheduledAt: time.Now(),
+		}
+		if err := runStore.CreateRun(ctx, run); err != nil {
+			t.Fatalf("Failed to create run: %v", err)
+		}
+		return run
+	}
+
+	short := createRun("test_worker_drain_short", "0.5")
+	long := createRun("test_worker_drain_long", "30")
+
+	worker.SetShutdownGracePeriod(time.Second)
+
+	runCtx, stop := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- worker.Run(runCtx) }()
+
+	// Stop the worker as soon as both runs are executing
+	deadline := time.Now().Add(5 * time.Second)
+	for worker.SlotsInUse() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("Runs never started")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	stop()
+
+	select {
+	case <-done:
+	case <-time.After(15 * time.Second):
+		t.Fatal("Worker did not drain")
+	}
+
+	// The short run finishes within the grace period, the long one doesn't
+	for run, want := range map[*types.Run]types.RunStatus{
+		short: types.RunStatusSucceeded,
+		long:  types.RunStatusInterrupted,
+	} {
+		updated, err := runStore.GetRun(ctx, run.ID)
+		if err != nil {
+			t.Fatalf("Failed to get run: %v", err)
+		}
+		if updated.Status != want {
+			t.Errorf("Expected run %s to be %s, got %s", run.ID, want, updated.Status)
+		}
+	}
+}
+
+func TestWorker_StoppingRunStaysTracked(t *testing.T) {
+	worker := NewWorker("test-worker-1", nil, nil, nil, zaptest.NewLogger(t))
+
+	runID := uuid.New()
+	ctx, cancel := context.WithCancelCause(context.Background())
+	worker.trackRun(runID, cancel)
+
+	worker.mu.Lock()
+	asked := worker.stopRun(runID, errCancelRequested)
+	again := worker.stopRun(runID, errCancelRequested)
+	worker.mu.Unlock()
+
+	if !asked || again {
+		t.Errorf("Expected the run to be asked to stop once, got %v then %v", asked, again)
+	}
+	if context.Cause(ctx) != errCancelRequested {
+		t.Errorf("Expected cause %v, got %v", errCancelRequested, context.Cause(ctx))
+	}
+
+	// It is still heartbeated until executeRun is done with it
+	if ids := worker.runningIDs(); len(ids) != 1 || ids[0] != runID {
+		t.Errorf("Expected the stopping run to stay tracked, got %v", ids)
+	}
+
+	worker.untrackRun(runID)
+	if ids := worker.runningIDs(); len(ids) != 0 {
+		t.Errorf("Expected no tracked runs, got %v", ids)
+	}
 }